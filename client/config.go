@@ -1,18 +1,122 @@
 package client
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	as "github.com/aerospike/aerospike-client-go/v8"
 )
 
+const (
+	URISchemeAerospike    = "aerospike"
+	URISchemeAerospikeTLS = "aerospikes"
+)
+
+var ErrRackIDRequired = fmt.Errorf("a rack id is required when rack awareness is enabled")
+
+var authModeNames = map[as.AuthMode]string{
+	as.AuthModeInternal: "INTERNAL",
+	as.AuthModeExternal: "EXTERNAL",
+	as.AuthModePKI:      "PKI",
+}
+
 // AerospikeConfig represents the intermediate configuration for an Aerospike
 // client. This can be constructed directly using flags.AerospikeFlags or
 type AerospikeConfig struct {
-	TLS                  *TLSConfig
-	User                 string
-	Password             string
-	Seeds                HostTLSPortSlice
+	TLS *TLSConfig
+	// ClientPolicy holds the timeout and connection pool settings. If nil the
+	// as.NewClientPolicy defaults are used.
+	ClientPolicy *ClientPolicyConfig
+	User         string
+	Password     string
+	Seeds        HostTLSPortSlice
+	// SRVSeeds are resolved using SRVResolver, or net.DefaultResolver if nil,
+	// when connecting and their seeds are added after Seeds.
+	SRVSeeds    []SRVSeed
+	SRVResolver SRVResolver
+	// RackIDs lists the preferred racks in order of preference.
+	RackIDs              []int
 	AuthMode             as.AuthMode
 	UseServicesAlternate bool
+	// RackAware makes the client prefer nodes on the racks in RackIDs. Reads
+	// only go to those nodes when their replica policy is as.PREFER_RACK.
+	RackAware bool
+}
+
+// ClientPolicyConfig holds the as.ClientPolicy settings exposed by
+// flags.AerospikeFlags. See as.ClientPolicy for their meaning.
+type ClientPolicyConfig struct {
+	ClusterName           string
+	Timeout               time.Duration
+	LoginTimeout          time.Duration
+	IdleTimeout           time.Duration
+	TendInterval          time.Duration
+	ConnectionQueueSize   int
+	MinConnectionsPerNode int
+	MaxErrorRate          int
+	ErrorRateWindow       int
+	FailIfNotConnected    bool
+}
+
+// NewDefaultClientPolicyConfig creates a ClientPolicyConfig with the
+// as.NewClientPolicy defaults.
+func NewDefaultClientPolicyConfig() *ClientPolicyConfig {
+	policy := as.NewClientPolicy()
+
+	return &ClientPolicyConfig{
+		Timeout:               policy.Timeout,
+		LoginTimeout:          policy.LoginTimeout,
+		IdleTimeout:           policy.IdleTimeout,
+		TendInterval:          policy.TendInterval,
+		ConnectionQueueSize:   policy.ConnectionQueueSize,
+		MinConnectionsPerNode: policy.MinConnectionsPerNode,
+		MaxErrorRate:          policy.MaxErrorRate,
+		ErrorRateWindow:       policy.ErrorRateWindow,
+		ClusterName:           policy.ClusterName,
+		FailIfNotConnected:    policy.FailIfNotConnected,
+	}
+}
+
+// apply copies the settings to clientPolicy.
+func (pc *ClientPolicyConfig) apply(clientPolicy *as.ClientPolicy) {
+	clientPolicy.Timeout = pc.Timeout
+	clientPolicy.LoginTimeout = pc.LoginTimeout
+	clientPolicy.IdleTimeout = pc.IdleTimeout
+	clientPolicy.TendInterval = pc.TendInterval
+	clientPolicy.ConnectionQueueSize = pc.ConnectionQueueSize
+	clientPolicy.MinConnectionsPerNode = pc.MinConnectionsPerNode
+	clientPolicy.MaxErrorRate = pc.MaxErrorRate
+	clientPolicy.ErrorRateWindow = pc.ErrorRateWindow
+	clientPolicy.ClusterName = pc.ClusterName
+	clientPolicy.FailIfNotConnected = pc.FailIfNotConnected
+}
+
+// uriQuery adds the settings that differ from the defaults to query.
+func (pc *ClientPolicyConfig) uriQuery(query url.Values) {
+	defaults := NewDefaultClientPolicyConfig()
+
+	for name, vals := range map[string][2]any{
+		"timeout":               {pc.Timeout, defaults.Timeout},
+		"login-timeout":         {pc.LoginTimeout, defaults.LoginTimeout},
+		"idle-timeout":          {pc.IdleTimeout, defaults.IdleTimeout},
+		"tend-interval":         {pc.TendInterval, defaults.TendInterval},
+		"conn-queue-size":       {pc.ConnectionQueueSize, defaults.ConnectionQueueSize},
+		"min-conns-per-node":    {pc.MinConnectionsPerNode, defaults.MinConnectionsPerNode},
+		"max-error-rate":        {pc.MaxErrorRate, defaults.MaxErrorRate},
+		"error-rate-window":     {pc.ErrorRateWindow, defaults.ErrorRateWindow},
+		"cluster-name":          {pc.ClusterName, defaults.ClusterName},
+		"fail-if-not-connected": {pc.FailIfNotConnected, defaults.FailIfNotConnected},
+	} {
+		if vals[0] != vals[1] {
+			query.Set(name, fmt.Sprint(vals[0]))
+		}
+	}
 }
 
 // NewDefaultAerospikeConfig creates a new default AerospikeConfig instance.
@@ -23,13 +127,27 @@ func NewDefaultAerospikeConfig() *AerospikeConfig {
 }
 
 // NewClientPolicy creates a new Aerospike client policy based on the
-// AerospikeConfig.
+// AerospikeConfig. It returns ErrRackIDRequired if RackAware is set without
+// any RackIDs.
 func (ac *AerospikeConfig) NewClientPolicy() (*as.ClientPolicy, error) {
+	if ac.RackAware && len(ac.RackIDs) == 0 {
+		return nil, ErrRackIDRequired
+	}
+
 	clientPolicy := as.NewClientPolicy()
 	clientPolicy.User = ac.User
 	clientPolicy.Password = ac.Password
 	clientPolicy.AuthMode = ac.AuthMode
 	clientPolicy.UseServicesAlternate = ac.UseServicesAlternate
+	clientPolicy.RackAware = ac.RackAware
+
+	if len(ac.RackIDs) != 0 {
+		clientPolicy.RackIds = slices.Clone(ac.RackIDs)
+	}
+
+	if ac.ClientPolicy != nil {
+		ac.ClientPolicy.apply(clientPolicy)
+	}
 
 	if ac.TLS != nil {
 		tlsConfig, err := ac.TLS.NewGoTLSConfig()
@@ -43,10 +161,54 @@ func (ac *AerospikeConfig) NewClientPolicy() (*as.ClientPolicy, error) {
 	return clientPolicy, nil
 }
 
+// NewHosts converts the seeds to Aerospike client hosts. SRVSeeds are
+// resolved without a deadline other than DefaultSRVLookupTimeout and their
+// seeds added after Seeds. An SRV seed that fails to resolve is left out
+// without an error, which leaves no hosts at all when --host only has srv:
+// entries. Use NewHostsContext to get the error and to control the lookups.
 func (ac *AerospikeConfig) NewHosts() []*as.Host {
+	seeds := slices.Clone(ac.Seeds)
+
+	for _, srv := range ac.SRVSeeds {
+		// NewHosts can not return the error, NewHostsContext does.
+		resolved, _ := srv.Resolve(context.Background(), ac.SRVResolver)
+		seeds = append(seeds, resolved...)
+	}
+
+	return newHosts(seeds)
+}
+
+// NewHostsContext is NewHosts with the SRV lookups bound to ctx. It fails if
+// any SRV seed does not resolve.
+func (ac *AerospikeConfig) NewHostsContext(ctx context.Context) ([]*as.Host, error) {
+	seeds, err := ac.resolveSRVSeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newHosts(seeds), nil
+}
+
+// resolveSRVSeeds returns Seeds followed by the seeds of every SRV seed.
+func (ac *AerospikeConfig) resolveSRVSeeds(ctx context.Context) (HostTLSPortSlice, error) {
+	seeds := slices.Clone(ac.Seeds)
+
+	for _, srv := range ac.SRVSeeds {
+		resolved, err := srv.Resolve(ctx, ac.SRVResolver)
+		if err != nil {
+			return nil, err
+		}
+
+		seeds = append(seeds, resolved...)
+	}
+
+	return seeds, nil
+}
+
+func newHosts(seeds HostTLSPortSlice) []*as.Host {
 	hosts := []*as.Host{}
 
-	for _, seed := range ac.Seeds {
+	for _, seed := range seeds {
 		host := as.NewHost(seed.Host, seed.Port)
 
 		if seed.TLSName != "" {
@@ -58,3 +220,171 @@ func (ac *AerospikeConfig) NewHosts() []*as.Host {
 
 	return hosts
 }
+
+// URI formats the config as an aerospike:// URI, or an aerospikes:// URI when
+// TLS is configured. Seeds are listed as host[:tls-name][:port] and other
+// settings as query parameters named after the equivalent flags. The TLS
+// certificates and keys are only included as the paths recorded in
+// TLS.Files, and the key password is never included. See
+// flags.ParseAerospikeURI for the inverse.
+func (ac *AerospikeConfig) URI() string {
+	return ac.formatURI(ac.Password)
+}
+
+// String returns the URI of the config with the password redacted. It has a
+// value receiver, as MarshalJSON does, so that printing an AerospikeConfig
+// that is not a pointer does not show the password.
+func (ac AerospikeConfig) String() string {
+	password := ac.Password
+
+	if password != "" {
+		password = RedactedSecret
+	}
+
+	return ac.formatURI(password)
+}
+
+func (ac *AerospikeConfig) formatURI(password string) string {
+	var uri strings.Builder
+
+	scheme := URISchemeAerospike
+
+	if ac.TLS != nil {
+		scheme = URISchemeAerospikeTLS
+	}
+
+	uri.WriteString(scheme + "://")
+
+	if ac.User != "" || password != "" {
+		userinfo := url.User(ac.User)
+
+		if password != "" {
+			userinfo = url.UserPassword(ac.User, password)
+		}
+
+		uri.WriteString(userinfo.String())
+		uri.WriteString("@")
+	}
+
+	hosts := []string{}
+
+	for _, seed := range ac.Seeds {
+		hosts = append(hosts, seed.String())
+	}
+
+	for _, srv := range ac.SRVSeeds {
+		hosts = append(hosts, srv.String())
+	}
+
+	uri.WriteString(strings.Join(hosts, ","))
+
+	if query := ac.uriQuery().Encode(); query != "" {
+		uri.WriteString("/?")
+		uri.WriteString(query)
+	}
+
+	return uri.String()
+}
+
+func (ac *AerospikeConfig) uriQuery() url.Values {
+	query := url.Values{}
+
+	if ac.AuthMode != as.AuthModeInternal {
+		query.Set("auth", authModeNames[ac.AuthMode])
+	}
+
+	if ac.UseServicesAlternate {
+		query.Set("services-alternate", "true")
+	}
+
+	if ac.RackAware {
+		query.Set("rack-aware", "true")
+	}
+
+	if len(ac.RackIDs) != 0 {
+		ids := []string{}
+
+		for _, id := range ac.RackIDs {
+			ids = append(ids, strconv.Itoa(id))
+		}
+
+		query.Set("rack-id", strings.Join(ids, ","))
+	}
+
+	if ac.ClientPolicy != nil {
+		ac.ClientPolicy.uriQuery(query)
+	}
+
+	if ac.TLS == nil {
+		return query
+	}
+
+	if protocols := tlsProtocolsString(ac.TLS.TLSProtocolsMinVersion, ac.TLS.TLSProtocolsMaxVersion); protocols != "" {
+		query.Set("tls-protocols", protocols)
+	}
+
+	if len(ac.TLS.TLSCipherSuites) != 0 {
+		names := []string{}
+
+		for _, id := range ac.TLS.TLSCipherSuites {
+			names = append(names, tls.CipherSuiteName(id))
+		}
+
+		query.Set("tls-cipher-suite", strings.Join(names, ":"))
+	}
+
+	for _, pin := range ac.TLS.Pins {
+		query.Add("tls-pin", pin.String())
+	}
+
+	if ac.TLS.ExcludeSystemCAs {
+		query.Set("tls-system-ca", "false")
+	}
+
+	if ac.TLS.PinOnly {
+		query.Set("tls-pin-only", "true")
+	}
+
+	if ac.TLS.RequireOCSPStaple {
+		query.Set("tls-require-ocsp-staple", "true")
+	}
+
+	for name, path := range map[string]string{
+		"tls-cafile":      ac.TLS.Files.RootCAFile,
+		"tls-capath":      ac.TLS.Files.RootCAPath,
+		"tls-certfile":    ac.TLS.Files.CertFile,
+		"tls-keyfile":     ac.TLS.Files.KeyFile,
+		"tls-pkcs12-file": ac.TLS.Files.PKCS12File,
+		"tls-crl-file":    ac.TLS.Files.CRLFile,
+		"tls-crl-path":    ac.TLS.Files.CRLPath,
+	} {
+		if path != "" {
+			query.Set(name, path)
+		}
+	}
+
+	return query
+}
+
+// tlsProtocolsString formats a protocol range in the --tls-protocols format.
+// It returns an empty string for the default or an unset range.
+func tlsProtocolsString(minVersion, maxVersion TLSProtocol) string {
+	if minVersion == 0 || maxVersion == 0 ||
+		(minVersion == VersionTLSDefaultMin && maxVersion == VersionTLSDefaultMax) {
+		return ""
+	}
+
+	protocols := []string{}
+
+	for p := minVersion; p <= maxVersion && p <= tls.VersionTLS13; p++ {
+		name := strings.Replace(p.String(), "V", "v", 1)
+
+		if minVersion == maxVersion {
+			return name
+		}
+
+		protocols = append(protocols, "+"+name)
+	}
+
+	return strings.Join(protocols, " ")
+}
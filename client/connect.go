@@ -0,0 +1,271 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v8"
+)
+
+const (
+	DefaultConnectAttempts   = 3
+	DefaultConnectBackoff    = 1 * time.Second
+	DefaultMaxConnectBackoff = 10 * time.Second
+	DefaultBackoffMultiplier = 2
+)
+
+// Client is the part of *as.Client used to manage a connection. It is what
+// Connect returns so that tools can substitute a fake in unit tests. Tools
+// that need the rest of the client can type assert it to *as.Client.
+type Client interface {
+	IsConnected() bool
+	Close()
+	GetNodes() []*as.Node
+	GetNodeNames() []string
+}
+
+var _ Client = (*as.Client)(nil)
+
+// NewClientFunc creates a client connected to hosts.
+type NewClientFunc func(policy *as.ClientPolicy, hosts ...*as.Host) (Client, error)
+
+// RetryPolicy configures how Connect retries failed connection attempts. The
+// delay before each retry starts at InitialBackoff and is multiplied by
+// Multiplier after every attempt, up to MaxBackoff.
+type RetryPolicy struct {
+	// MaxAttempts is the number of connection attempts. If zero
+	// DefaultConnectAttempts is used.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. If zero
+	// DefaultConnectBackoff is used.
+	InitialBackoff time.Duration
+	// MaxBackoff limits the delay between attempts. If zero
+	// DefaultMaxConnectBackoff is used.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after each attempt. If zero
+	// DefaultBackoffMultiplier is used.
+	Multiplier float64
+}
+
+// NewDefaultRetryPolicy creates a RetryPolicy with the default values.
+func NewDefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    DefaultConnectAttempts,
+		InitialBackoff: DefaultConnectBackoff,
+		MaxBackoff:     DefaultMaxConnectBackoff,
+		Multiplier:     DefaultBackoffMultiplier,
+	}
+}
+
+func (p *RetryPolicy) withDefaults() RetryPolicy {
+	policy := *p
+	defaults := NewDefaultRetryPolicy()
+
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaults.MaxAttempts
+	}
+
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaults.InitialBackoff
+	}
+
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaults.MaxBackoff
+	}
+
+	if policy.Multiplier <= 0 {
+		policy.Multiplier = defaults.Multiplier
+	}
+
+	return policy
+}
+
+// backoff returns the delay after the given attempt, starting at 1.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff)
+
+	for range attempt - 1 {
+		backoff *= p.Multiplier
+
+		if backoff >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+
+	return min(time.Duration(backoff), p.MaxBackoff)
+}
+
+// ConnectOptions configures Connect.
+type ConnectOptions struct {
+	// Dialer is used to check the seeds after the last failed attempt. If nil
+	// a net.Dialer is used.
+	Dialer ContextDialer
+	// NewClient creates the client for each attempt. If nil
+	// as.NewClientWithPolicyAndHost is used.
+	NewClient NewClientFunc
+	Retry     RetryPolicy
+}
+
+// ErrConnect is returned by Connect when every attempt failed. Err holds the
+// error of the last attempt and Seeds the seeds that could not be reached
+// when checked after the last attempt. errors.Is and errors.As match Err and
+// the errors of Seeds.
+type ErrConnect struct {
+	Err      error
+	Seeds    []SeedResult
+	Attempts int
+}
+
+func (e *ErrConnect) Error() string {
+	msg := fmt.Sprintf("failed to connect to the Aerospike cluster after %d attempt(s): %s", e.Attempts, e.Err)
+
+	if len(e.Seeds) != 0 {
+		seeds := []string{}
+
+		for _, result := range e.Seeds {
+			seeds = append(seeds, result.Err.Error())
+		}
+
+		msg = fmt.Sprintf("%s: %s", msg, strings.Join(seeds, "; "))
+	}
+
+	return msg
+}
+
+func (e *ErrConnect) Unwrap() []error {
+	errs := []error{e.Err}
+
+	for _, result := range e.Seeds {
+		errs = append(errs, result.Err)
+	}
+
+	return errs
+}
+
+// Connect creates a client from the config and waits until it is connected to
+// the cluster, retrying according to opts.Retry. It returns early with the
+// context's error when ctx is done. Errors building the client policy or
+// resolving the SRV seeds are returned without retrying. If every attempt
+// fails the returned error is an *ErrConnect.
+func Connect(ctx context.Context, ac *AerospikeConfig, opts ConnectOptions) (Client, error) {
+	policy, err := ac.NewClientPolicy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client policy: %w", err)
+	}
+
+	newClient := opts.NewClient
+	if newClient == nil {
+		newClient = newAerospikeClient
+	}
+
+	seeds, err := ac.resolveSRVSeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := newHosts(seeds)
+	retry := opts.Retry.withDefaults()
+
+	var lastErr error
+
+	for attempt := 1; attempt <= retry.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			if err := sleep(ctx, retry.backoff(attempt-1)); err != nil {
+				return nil, &ErrConnect{Attempts: attempt - 1, Err: withLastError(err, lastErr)}
+			}
+		}
+
+		asClient, err := connectOnce(ctx, newClient, policy, hosts)
+		if err == nil {
+			return asClient, nil
+		}
+
+		lastErr = err
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, &ErrConnect{Attempts: attempt, Err: withLastError(ctxErr, lastErr)}
+		}
+	}
+
+	results := CheckSeeds(ctx, opts.Dialer, seeds, DefaultPort, policy.Timeout)
+	failures := []SeedResult{}
+
+	for _, result := range results {
+		if result.Err != nil {
+			failures = append(failures, result)
+		}
+	}
+
+	return nil, &ErrConnect{Attempts: retry.MaxAttempts, Err: lastErr, Seeds: failures}
+}
+
+// connectOnce makes a single connection attempt. The client library does not
+// accept a context, so the attempt is abandoned, and its client closed once
+// created, when ctx is done first.
+func connectOnce(ctx context.Context, newClient NewClientFunc, policy *as.ClientPolicy, hosts []*as.Host) (
+	Client, error,
+) {
+	type result struct {
+		client Client
+		err    error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		asClient, err := newClient(policy, hosts...)
+		if err == nil && !asClient.IsConnected() {
+			asClient.Close()
+			asClient, err = nil, fmt.Errorf("client is not connected")
+		}
+
+		done <- result{asClient, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.client, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.client != nil {
+				r.client.Close()
+			}
+		}()
+
+		return nil, ctx.Err()
+	}
+}
+
+// withLastError wraps the context error ctxErr with the error of the last
+// attempt, unless the attempt itself failed because of the context.
+func withLastError(ctxErr, lastErr error) error {
+	if errors.Is(lastErr, ctxErr) {
+		return ctxErr
+	}
+
+	return fmt.Errorf("%w: %w", ctxErr, lastErr)
+}
+
+func newAerospikeClient(policy *as.ClientPolicy, hosts ...*as.Host) (Client, error) {
+	asClient, err := as.NewClientWithPolicyAndHost(policy, hosts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return asClient, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	as "github.com/aerospike/aerospike-client-go/v8"
+)
+
+type fakeClient struct {
+	connected bool
+	closed    atomic.Bool
+}
+
+func (c *fakeClient) IsConnected() bool      { return c.connected }
+func (c *fakeClient) Close()                 { c.closed.Store(true) }
+func (c *fakeClient) GetNodes() []*as.Node   { return nil }
+func (c *fakeClient) GetNodeNames() []string { return nil }
+
+var errFakeConnect = errors.New("fake connection error")
+
+// fakeNewClient fails until the given attempt and then returns client.
+func fakeNewClient(succeedAt int, client *fakeClient, attempts *int) NewClientFunc {
+	return func(_ *as.ClientPolicy, _ ...*as.Host) (Client, error) {
+		*attempts++
+
+		if *attempts < succeedAt {
+			return nil, errFakeConnect
+		}
+
+		return client, nil
+	}
+}
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     time.Millisecond,
+}
+
+func TestConnect(t *testing.T) {
+	expected := &fakeClient{connected: true}
+	attempts := 0
+
+	actual, err := Connect(context.Background(), NewDefaultAerospikeConfig(), ConnectOptions{
+		Retry:     testRetryPolicy,
+		NewClient: fakeNewClient(3, expected, &attempts),
+	})
+	if err != nil {
+		t.Fatalf("Connect() returned an unexpected error: %v", err)
+	}
+
+	if actual != expected || attempts != 3 {
+		t.Errorf("Connect() = %v after %d attempts, want %v after 3 attempts", actual, attempts, expected)
+	}
+}
+
+func TestConnectFailure(t *testing.T) {
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+
+	closed.Close()
+
+	ac := &AerospikeConfig{
+		Seeds: HostTLSPortSlice{{Host: "127.0.0.1", Port: closedPort}},
+	}
+	notConnected := &fakeClient{}
+	attempts := 0
+
+	_, err := Connect(context.Background(), ac, ConnectOptions{
+		Retry:     testRetryPolicy,
+		NewClient: fakeNewClient(1, notConnected, &attempts),
+	})
+
+	var connectErr *ErrConnect
+
+	if !errors.As(err, &connectErr) {
+		t.Fatalf("Connect() error = %v, want %T", err, connectErr)
+	}
+
+	if connectErr.Attempts != 3 || attempts != 3 {
+		t.Errorf("Connect() made %d attempts and reported %d, want 3", attempts, connectErr.Attempts)
+	}
+
+	if !notConnected.closed.Load() {
+		t.Errorf("Connect() did not close the client that was not connected")
+	}
+
+	if len(connectErr.Seeds) != 1 || !errors.Is(err, ErrSeedUnreachable) {
+		t.Errorf("Connect() error = %v, want a failure for seed %v", err, ac.Seeds[0])
+	}
+
+	ac.RackAware = true
+
+	if _, err := Connect(context.Background(), ac, ConnectOptions{}); !errors.Is(err, ErrRackIDRequired) {
+		t.Errorf("Connect() error = %v, want %v", err, ErrRackIDRequired)
+	}
+}
+
+func TestConnectCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+
+	// Cancel while Connect waits to retry the first attempt.
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := Connect(ctx, NewDefaultAerospikeConfig(), ConnectOptions{
+		Retry:     RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Hour, MaxBackoff: time.Hour},
+		NewClient: fakeNewClient(10, &fakeClient{connected: true}, &attempts),
+	})
+
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errFakeConnect) {
+		t.Errorf("Connect() error = %v, want %v wrapping %v", err, context.Canceled, errFakeConnect)
+	}
+
+	if attempts != 1 {
+		t.Errorf("Connect() made %d attempts after being canceled, want 1", attempts)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	blocked := make(chan struct{})
+	abandoned := &fakeClient{connected: true}
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err = Connect(ctx, NewDefaultAerospikeConfig(), ConnectOptions{
+		NewClient: func(_ *as.ClientPolicy, _ ...*as.Host) (Client, error) {
+			<-blocked
+			return abandoned, nil
+		},
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Connect() error = %v, want %v", err, context.Canceled)
+	}
+
+	close(blocked)
+
+	for range 100 {
+		if abandoned.closed.Load() {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Errorf("Connect() did not close the client of the abandoned attempt")
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{}
+	policy = policy.withDefaults()
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second}
+
+	for i, backoff := range expected {
+		if actual := policy.backoff(i + 1); actual != backoff {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, actual, backoff)
+		}
+	}
+}
@@ -0,0 +1,442 @@
+package client
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// DefaultTLSExpiryWarning is how long before a certificate expires that
+// diagnostics start warning about it.
+const DefaultTLSExpiryWarning = 30 * 24 * time.Hour
+
+// TLSFindingSeverity is how serious a TLSFinding is.
+type TLSFindingSeverity int
+
+const (
+	TLSFindingInfo TLSFindingSeverity = iota
+	TLSFindingWarning
+	TLSFindingError
+)
+
+func (s TLSFindingSeverity) String() string {
+	switch s {
+	case TLSFindingInfo:
+		return "INFO"
+	case TLSFindingWarning:
+		return "WARNING"
+	case TLSFindingError:
+		return "ERROR"
+	}
+
+	return ""
+}
+
+// TLSFindingKind identifies the check that produced a TLSFinding.
+type TLSFindingKind string
+
+const (
+	// TLSFindingValidity reports the validity period of a certificate.
+	TLSFindingValidity TLSFindingKind = "validity"
+	// TLSFindingNotYetValid reports a certificate whose NotBefore is in the future.
+	TLSFindingNotYetValid TLSFindingKind = "not-yet-valid"
+	// TLSFindingExpired reports a certificate whose NotAfter is in the past.
+	TLSFindingExpired TLSFindingKind = "expired"
+	// TLSFindingExpiresSoon reports a certificate expiring within the warning period.
+	TLSFindingExpiresSoon TLSFindingKind = "expires-soon"
+	// TLSFindingInvalidCertificate reports a certificate that could not be parsed.
+	TLSFindingInvalidCertificate TLSFindingKind = "invalid-certificate"
+	// TLSFindingInvalidKey reports a private key that could not be decrypted or parsed.
+	TLSFindingInvalidKey TLSFindingKind = "invalid-key"
+	// TLSFindingKeyMismatch reports a private key that does not belong to the certificate.
+	TLSFindingKeyMismatch TLSFindingKind = "key-mismatch"
+	// TLSFindingMissingClientAuth reports a client certificate without the clientAuth extended key usage.
+	TLSFindingMissingClientAuth TLSFindingKind = "missing-client-auth"
+	// TLSFindingChainInvalid reports a certificate that does not verify against the CA certificates.
+	TLSFindingChainInvalid TLSFindingKind = "chain-invalid"
+	// TLSFindingTLSNameMismatch reports a server certificate without the host's TLS name in its SANs.
+	TLSFindingTLSNameMismatch TLSFindingKind = "tls-name-mismatch"
+	// TLSFindingUnreachable reports a host whose certificate could not be fetched.
+	TLSFindingUnreachable TLSFindingKind = "unreachable"
+)
+
+// TLSFinding is a single result of diagnosing a TLSConfig.
+type TLSFinding struct {
+	// Certificate is the certificate the finding is about, if any.
+	Certificate *x509.Certificate
+	Kind        TLSFindingKind
+	// Source names what the finding is about, e.g. "client certificate",
+	// "CA input 2" or "host 10.0.0.1:tls-name:4333".
+	Source   string
+	Message  string
+	Severity TLSFindingSeverity
+}
+
+func (f TLSFinding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.Severity, f.Source, f.Message)
+}
+
+// TLSDiagnoseOptions configures TLSConfig diagnostics.
+type TLSDiagnoseOptions struct {
+	// Now is the time certificates are checked against. Defaults to the
+	// current time.
+	Now time.Time
+	// ExpiryWarning is how long before a certificate expires to warn about
+	// it. Defaults to DefaultTLSExpiryWarning.
+	ExpiryWarning time.Duration
+}
+
+func (opts TLSDiagnoseOptions) withDefaults() TLSDiagnoseOptions {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	if opts.ExpiryWarning == 0 {
+		opts.ExpiryWarning = DefaultTLSExpiryWarning
+	}
+
+	return opts
+}
+
+// tlsDiagnosis accumulates findings.
+type tlsDiagnosis struct {
+	opts     TLSDiagnoseOptions
+	findings []TLSFinding
+}
+
+func (d *tlsDiagnosis) add(
+	kind TLSFindingKind, severity TLSFindingSeverity, source string, cert *x509.Certificate, format string, a ...any,
+) {
+	d.findings = append(d.findings, TLSFinding{
+		Kind:        kind,
+		Severity:    severity,
+		Source:      source,
+		Certificate: cert,
+		Message:     fmt.Sprintf(format, a...),
+	})
+}
+
+// checkValidity reports the validity period of a certificate and whether it
+// is not yet valid, expired or expiring soon.
+func (d *tlsDiagnosis) checkValidity(source string, cert *x509.Certificate) {
+	now := d.opts.Now
+	subject := cert.Subject.String()
+
+	d.add(TLSFindingValidity, TLSFindingInfo, source, cert, "%q is valid from %s until %s",
+		subject, cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339))
+
+	switch {
+	case now.Before(cert.NotBefore):
+		d.add(TLSFindingNotYetValid, TLSFindingError, source, cert, "%q is not valid until %s",
+			subject, cert.NotBefore.Format(time.RFC3339))
+	case now.After(cert.NotAfter):
+		d.add(TLSFindingExpired, TLSFindingError, source, cert, "%q expired at %s",
+			subject, cert.NotAfter.Format(time.RFC3339))
+	case now.Add(d.opts.ExpiryWarning).After(cert.NotAfter):
+		d.add(TLSFindingExpiresSoon, TLSFindingWarning, source, cert, "%q expires in %s at %s",
+			subject, cert.NotAfter.Sub(now).Round(time.Minute), cert.NotAfter.Format(time.RFC3339))
+	}
+}
+
+// parsePEMCertificates parses every "CERTIFICATE" block, reporting blocks that
+// fail to parse.
+func (d *tlsDiagnosis) parsePEMCertificates(source string, data []byte) []*x509.Certificate {
+	certs := []*x509.Certificate{}
+
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			d.add(TLSFindingInvalidCertificate, TLSFindingError, source, nil, "failed to parse certificate: %s", err)
+			continue
+		}
+
+		certs = append(certs, cert)
+	}
+
+	return certs
+}
+
+// verifyChain reports a leaf that does not verify against roots.
+func (d *tlsDiagnosis) verifyChain(
+	source string, severity TLSFindingSeverity, chain []*x509.Certificate, roots *x509.CertPool,
+) {
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+		CurrentTime:   d.opts.Now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+
+	for _, cert := range chain[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	if _, err := chain[0].Verify(opts); err != nil {
+		d.add(TLSFindingChainInvalid, severity, source, chain[0],
+			"%q does not verify against the configured CA certificates: %s", chain[0].Subject, err)
+	}
+}
+
+// rootCAs returns the CA pool NewGoTLSConfig would use, reporting any CA
+// certificate that fails to parse and the validity of the rest.
+func (d *tlsDiagnosis) rootCAs(tc *TLSConfig) *x509.CertPool {
+	for i, caBytes := range tc.RootCA {
+		if len(caBytes) == 0 {
+			continue
+		}
+
+		source := fmt.Sprintf("CA input %d", i+1)
+
+		for _, cert := range d.parsePEMCertificates(source, caBytes) {
+			d.checkValidity(source, cert)
+		}
+	}
+
+	opts := CACertOptions{ExcludeSystemCAs: tc.ExcludeSystemCAs, Strict: true}
+
+	roots, err := LoadCACertsWithOptions(tc.RootCA, opts)
+	if err != nil {
+		// Already reported above, continue with the certificates that parse.
+		opts.Strict = false
+		roots, _ = LoadCACertsWithOptions(tc.RootCA, opts)
+	}
+
+	return roots
+}
+
+// Diagnose parses the configured CA certificates, client certificate and key
+// and reports problems that would make a TLS connection fail, along with the
+// validity period of every certificate. Tools can print the findings before
+// connecting, or after a handshake error, and warn ahead of expiry. See
+// DiagnoseHosts to check the certificates presented by the servers.
+func (tc *TLSConfig) Diagnose(opts TLSDiagnoseOptions) []TLSFinding {
+	d := &tlsDiagnosis{opts: opts.withDefaults()}
+	roots := d.rootCAs(tc)
+
+	var (
+		chain []*x509.Certificate
+		key   crypto.PrivateKey
+	)
+
+	source := "client certificate"
+
+	if len(tc.PKCS12) > 0 {
+		source = "PKCS#12 bundle"
+
+		cert, caCerts, err := LoadPKCS12(tc.PKCS12, tc.KeyPass)
+		if err != nil {
+			d.add(TLSFindingInvalidCertificate, TLSFindingError, source, nil, "%s", err)
+			return d.findings
+		}
+
+		// NewGoTLSConfig trusts the CA certificates in the bundle as well.
+		for _, caCert := range caCerts {
+			roots.AddCert(caCert)
+		}
+
+		chain = append([]*x509.Certificate{cert.Leaf}, caCerts...)
+		key = cert.PrivateKey
+	} else if len(tc.Cert) > 0 {
+		chain = d.parsePEMCertificates(source, tc.Cert)
+
+		if len(chain) == 0 {
+			d.add(TLSFindingInvalidCertificate, TLSFindingError, source, nil, "no PEM certificates found")
+		}
+
+		if len(tc.Key) > 0 {
+			key = d.parsePrivateKey(tc.Key, tc.KeyPass)
+		}
+	}
+
+	if len(chain) == 0 {
+		return d.findings
+	}
+
+	leaf := chain[0]
+
+	for _, cert := range chain {
+		d.checkValidity(source, cert)
+	}
+
+	if key != nil {
+		if pub, ok := key.(crypto.Signer); !ok || !publicKeysEqual(pub.Public(), leaf.PublicKey) {
+			d.add(TLSFindingKeyMismatch, TLSFindingError, source, leaf,
+				"the private key does not match the public key of %q", leaf.Subject)
+		}
+	}
+
+	if len(leaf.ExtKeyUsage) > 0 && !slices.Contains(leaf.ExtKeyUsage, x509.ExtKeyUsageClientAuth) &&
+		!slices.Contains(leaf.ExtKeyUsage, x509.ExtKeyUsageAny) {
+		d.add(TLSFindingMissingClientAuth, TLSFindingError, source, leaf,
+			"%q does not allow the clientAuth extended key usage", leaf.Subject)
+	}
+
+	// The server may trust other CAs than the client so this is a warning.
+	d.verifyChain(source, TLSFindingWarning, chain, roots)
+
+	return d.findings
+}
+
+func (d *tlsDiagnosis) parsePrivateKey(keyFileBytes, keyPassBytes []byte) crypto.PrivateKey {
+	const source = "client key"
+
+	keyPEM, err := decryptKeyPEM(keyFileBytes, keyPassBytes)
+	if err != nil {
+		d.add(TLSFindingInvalidKey, TLSFindingError, source, nil, "%s", err)
+		return nil
+	}
+
+	block, _ := pem.Decode(keyPEM)
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key
+	}
+
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key
+	}
+
+	d.add(TLSFindingInvalidKey, TLSFindingError, source, nil, "failed to parse %q block as a private key", block.Type)
+
+	return nil
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	key, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && key.Equal(b)
+}
+
+// DiagnoseServerCertificates reports problems with the certificate chain
+// presented by a host: certificates that are not yet valid, expired or
+// expiring soon, a chain that does not verify against the configured CA
+// certificates and a TLS name that is not in the certificate's SANs.
+func (tc *TLSConfig) DiagnoseServerCertificates(
+	host *HostTLSPort, chain []*x509.Certificate, opts TLSDiagnoseOptions,
+) []TLSFinding {
+	d := &tlsDiagnosis{opts: opts.withDefaults()}
+	source := "host " + host.String()
+
+	if len(chain) == 0 {
+		d.add(TLSFindingInvalidCertificate, TLSFindingError, source, nil, "no certificate presented")
+		return d.findings
+	}
+
+	roots, err := LoadCACertsWithOptions(tc.RootCA, CACertOptions{
+		ExcludeSystemCAs: tc.ExcludeSystemCAs,
+		Strict:           true,
+	})
+	if err != nil {
+		d.add(TLSFindingInvalidCertificate, TLSFindingError, source, nil, "%s", err)
+		return d.findings
+	}
+
+	for _, cert := range chain {
+		d.checkValidity(source, cert)
+	}
+
+	leaf := chain[0]
+
+	d.verifyChain(source, TLSFindingError, chain, roots)
+
+	if host.TLSName != "" {
+		if err := leaf.VerifyHostname(host.TLSName); err != nil {
+			d.add(TLSFindingTLSNameMismatch, TLSFindingError, source, leaf,
+				"TLS name %q is not in the SANs of %q: DNS names %v, IP addresses %v",
+				host.TLSName, leaf.Subject, leaf.DNSNames, leaf.IPAddresses)
+		}
+	}
+
+	return d.findings
+}
+
+// FetchServerCertificates connects to the host and returns the certificate
+// chain it presents without verifying it, so that it can be passed to
+// DiagnoseServerCertificates. The chain is returned even if the handshake
+// fails later, for example because the server rejects our client certificate.
+func (tc *TLSConfig) FetchServerCertificates(ctx context.Context, host *HostTLSPort) ([]*x509.Certificate, error) {
+	base, err := tc.NewGoTLSConfig()
+	if err != nil || base == nil {
+		base = &tls.Config{} //nolint:gosec // only used to fetch the server certificate
+	}
+
+	var chain []*x509.Certificate
+
+	tlsConfig := base.Clone()
+	tlsConfig.ServerName = host.TLSName
+	tlsConfig.InsecureSkipVerify = true //nolint:gosec // the certificate is only fetched for diagnostics
+	tlsConfig.VerifyConnection = nil
+	tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+
+			chain = append(chain, cert)
+		}
+
+		return nil
+	}
+
+	port := host.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host.Host, strconv.Itoa(port)))
+	if err == nil {
+		conn.Close()
+	}
+
+	if len(chain) == 0 {
+		if err == nil {
+			err = fmt.Errorf("server did not present a certificate")
+		}
+
+		return nil, fmt.Errorf("failed to fetch the certificate of %s: %w", host, err)
+	}
+
+	return chain, nil
+}
+
+// DiagnoseHosts fetches the certificate chain of every host and diagnoses it
+// with DiagnoseServerCertificates. Hosts that can not be reached are
+// reported as TLSFindingUnreachable.
+func (tc *TLSConfig) DiagnoseHosts(ctx context.Context, hosts HostTLSPortSlice, opts TLSDiagnoseOptions) []TLSFinding {
+	findings := []TLSFinding{}
+
+	for _, host := range hosts {
+		chain, err := tc.FetchServerCertificates(ctx, host)
+		if err != nil {
+			findings = append(findings, TLSFinding{
+				Kind:     TLSFindingUnreachable,
+				Severity: TLSFindingError,
+				Source:   "host " + host.String(),
+				Message:  err.Error(),
+			})
+
+			continue
+		}
+
+		findings = append(findings, tc.DiagnoseServerCertificates(host, chain, opts)...)
+	}
+
+	return findings
+}
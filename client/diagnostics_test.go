@@ -0,0 +1,280 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aerospike/tools-common-go/internal/tlstest"
+)
+
+func findingKinds(findings []TLSFinding, minSeverity TLSFindingSeverity) []TLSFindingKind {
+	kinds := []TLSFindingKind{}
+
+	for _, finding := range findings {
+		if finding.Severity >= minSeverity {
+			kinds = append(kinds, finding.Kind)
+		}
+	}
+
+	return kinds
+}
+
+func TestTLSConfigDiagnose(t *testing.T) {
+	rootCA, _ := tlstest.GenerateCert()
+	cert, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(50))
+	pfx, _ := tlstest.GeneratePKCS12(tlstest.NewLeafTemplate(51), "fakepassphrase")
+
+	expiringTemplate := tlstest.NewLeafTemplate(52)
+	expiringTemplate.NotAfter = time.Now().Add(24 * time.Hour)
+	expiringCert, _ := tlstest.GenerateLeafCert(expiringTemplate)
+
+	expiredTemplate := tlstest.NewLeafTemplate(53)
+	expiredTemplate.NotBefore = time.Now().AddDate(-1, 0, 0)
+	expiredTemplate.NotAfter = time.Now().Add(-time.Hour)
+	expiredCert, _ := tlstest.GenerateLeafCert(expiredTemplate)
+
+	futureTemplate := tlstest.NewLeafTemplate(54)
+	futureTemplate.NotBefore = time.Now().Add(time.Hour)
+	futureCert, _ := tlstest.GenerateLeafCert(futureTemplate)
+
+	serverOnlyTemplate := tlstest.NewLeafTemplate(55)
+	serverOnlyTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	serverOnlyCert, _ := tlstest.GenerateLeafCert(serverOnlyTemplate)
+
+	testCases := []struct {
+		name     string
+		tc       *TLSConfig
+		expected []TLSFindingKind
+	}{
+		{
+			name: "Valid",
+			tc: &TLSConfig{
+				RootCA: [][]byte{rootCA},
+				Cert:   cert,
+				Key:    tlstest.LeafKeyFileBytes,
+			},
+			expected: []TLSFindingKind{},
+		},
+		{
+			name: "ValidPKCS12",
+			tc: &TLSConfig{
+				PKCS12:           pfx,
+				KeyPass:          []byte("fakepassphrase"),
+				ExcludeSystemCAs: true,
+			},
+			expected: []TLSFindingKind{},
+		},
+		{
+			name: "ExpiresSoon",
+			tc: &TLSConfig{
+				RootCA: [][]byte{rootCA},
+				Cert:   expiringCert,
+				Key:    tlstest.LeafKeyFileBytes,
+			},
+			expected: []TLSFindingKind{TLSFindingExpiresSoon},
+		},
+		{
+			name: "Expired",
+			tc: &TLSConfig{
+				RootCA: [][]byte{rootCA},
+				Cert:   expiredCert,
+			},
+			expected: []TLSFindingKind{TLSFindingExpired, TLSFindingChainInvalid},
+		},
+		{
+			name: "NotYetValid",
+			tc: &TLSConfig{
+				RootCA: [][]byte{rootCA},
+				Cert:   futureCert,
+			},
+			expected: []TLSFindingKind{TLSFindingNotYetValid, TLSFindingChainInvalid},
+		},
+		{
+			name: "KeyMismatch",
+			tc: &TLSConfig{
+				RootCA: [][]byte{rootCA},
+				Cert:   cert,
+				Key:    tlstest.KeyFileBytes,
+			},
+			expected: []TLSFindingKind{TLSFindingKeyMismatch},
+		},
+		{
+			name: "InvalidKey",
+			tc: &TLSConfig{
+				RootCA: [][]byte{rootCA},
+				Cert:   cert,
+				Key:    encryptPKCS8Block([]byte("fakepassphrase")),
+			},
+			expected: []TLSFindingKind{TLSFindingInvalidKey},
+		},
+		{
+			name: "MissingClientAuth",
+			tc: &TLSConfig{
+				RootCA: [][]byte{rootCA},
+				Cert:   serverOnlyCert,
+			},
+			expected: []TLSFindingKind{TLSFindingMissingClientAuth},
+		},
+		{
+			name: "UntrustedChain",
+			tc: &TLSConfig{
+				Cert:             cert,
+				ExcludeSystemCAs: true,
+			},
+			expected: []TLSFindingKind{TLSFindingChainInvalid},
+		},
+		{
+			name: "InvalidCA",
+			tc: &TLSConfig{
+				RootCA: [][]byte{
+					rootCA,
+					pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("not a certificate")}),
+					// Inputs without certificates, e.g. CRLs in a CA path, are ignored.
+					[]byte("not a certificate"),
+				},
+				Cert: cert,
+			},
+			expected: []TLSFindingKind{TLSFindingInvalidCertificate},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			findings := tc.tc.Diagnose(TLSDiagnoseOptions{})
+
+			if actual := findingKinds(findings, TLSFindingWarning); !slices.Equal(actual, tc.expected) {
+				t.Errorf("Diagnose() returned %v, want %v: %v", actual, tc.expected, findings)
+			}
+		})
+	}
+}
+
+func TestTLSConfigDiagnoseValidity(t *testing.T) {
+	rootCA, _ := tlstest.GenerateCert()
+	template := tlstest.NewLeafTemplate(56)
+	cert, _ := tlstest.GenerateLeafCert(template)
+
+	tc := &TLSConfig{
+		RootCA: [][]byte{rootCA},
+		Cert:   cert,
+	}
+
+	findings := tc.Diagnose(TLSDiagnoseOptions{})
+	validity := 0
+
+	for _, finding := range findings {
+		if finding.Kind != TLSFindingValidity {
+			continue
+		}
+
+		validity++
+
+		if finding.Certificate == nil || finding.Certificate.NotAfter.IsZero() {
+			t.Errorf("validity finding %v is missing its certificate", finding)
+		}
+	}
+
+	// The CA and the client certificate.
+	if validity != 2 {
+		t.Errorf("Diagnose() returned %d validity findings, want 2: %v", validity, findings)
+	}
+
+	findings = tc.Diagnose(TLSDiagnoseOptions{Now: template.NotAfter.Add(-time.Hour), ExpiryWarning: 2 * time.Hour})
+
+	expected := []TLSFindingKind{TLSFindingExpiresSoon}
+
+	if actual := findingKinds(findings, TLSFindingWarning); !slices.Equal(actual, expected) {
+		t.Errorf("Diagnose() returned %v, want %v", actual, expected)
+	}
+}
+
+func TestTLSConfigDiagnoseHosts(t *testing.T) {
+	rootCA, _ := tlstest.GenerateCert()
+	certPEM, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(57))
+
+	serverCert, err := tls.X509KeyPair(certPEM, tlstest.LeafKeyFileBytes)
+	if err != nil {
+		t.Fatalf("failed to load server certificate: %v", err)
+	}
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{ //nolint:gosec // test server
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAnyClientCert,
+	})
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			_ = conn.(*tls.Conn).Handshake()
+
+			conn.Close()
+		}
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+
+	closed.Close()
+
+	testCases := []struct {
+		name     string
+		tc       *TLSConfig
+		host     *HostTLSPort
+		expected []TLSFindingKind
+	}{
+		{
+			name:     "Valid",
+			tc:       &TLSConfig{RootCA: [][]byte{rootCA}},
+			host:     &HostTLSPort{Host: "127.0.0.1", TLSName: "localhost", Port: port},
+			expected: []TLSFindingKind{},
+		},
+		{
+			name:     "TLSNameMismatch",
+			tc:       &TLSConfig{RootCA: [][]byte{rootCA}},
+			host:     &HostTLSPort{Host: "127.0.0.1", TLSName: "aerospike.example.com", Port: port},
+			expected: []TLSFindingKind{TLSFindingTLSNameMismatch},
+		},
+		{
+			name:     "UntrustedChain",
+			tc:       &TLSConfig{ExcludeSystemCAs: true},
+			host:     &HostTLSPort{Host: "127.0.0.1", TLSName: "localhost", Port: port},
+			expected: []TLSFindingKind{TLSFindingChainInvalid},
+		},
+		{
+			name:     "Unreachable",
+			tc:       &TLSConfig{RootCA: [][]byte{rootCA}},
+			host:     &HostTLSPort{Host: "127.0.0.1", TLSName: "localhost", Port: closedPort},
+			expected: []TLSFindingKind{TLSFindingUnreachable},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			findings := tc.tc.DiagnoseHosts(ctx, HostTLSPortSlice{tc.host}, TLSDiagnoseOptions{})
+
+			if actual := findingKinds(findings, TLSFindingWarning); !slices.Equal(actual, tc.expected) {
+				t.Errorf("DiagnoseHosts() returned %v, want %v: %v", actual, tc.expected, findings)
+			}
+		})
+	}
+}
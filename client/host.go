@@ -2,6 +2,9 @@ package client
 
 import (
 	"fmt"
+	"net"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -14,6 +17,63 @@ type HostTLSPort struct {
 const DefaultPort = 3000
 const DefaultIPv4 = "127.0.0.1"
 
+const (
+	minPort = 1
+	maxPort = 65535
+)
+
+var hostTLSPortRegexes = func() []*regexp.Regexp {
+	ipv6HostPattern := `^\[(?P<host>[^\]]*)\]`
+	hostPattern := `^(?P<host>[^:\[\]]+)` // matches ipv4 and hostname
+	tlsNamePattern := `(?P<tlsName>[^:]+)`
+	portPattern := `(?P<port>\d+)$`
+
+	// The order is important since a tls-name can look like a port and the
+	// ipv4 patterns would otherwise match parts of an ipv6 address.
+	return []*regexp.Regexp{
+		regexp.MustCompile(fmt.Sprintf("%s:%s:%s", ipv6HostPattern, tlsNamePattern, portPattern)),
+		regexp.MustCompile(fmt.Sprintf("%s:%s", ipv6HostPattern, portPattern)),
+		regexp.MustCompile(fmt.Sprintf("%s:%s$", ipv6HostPattern, tlsNamePattern)),
+		regexp.MustCompile(fmt.Sprintf("%s$", ipv6HostPattern)),
+		regexp.MustCompile(fmt.Sprintf("%s:%s:%s", hostPattern, tlsNamePattern, portPattern)),
+		regexp.MustCompile(fmt.Sprintf("%s:%s", hostPattern, portPattern)),
+		regexp.MustCompile(fmt.Sprintf("%s:%s$", hostPattern, tlsNamePattern)),
+		regexp.MustCompile(fmt.Sprintf("%s$", hostPattern)),
+	}
+}()
+
+// ErrInvalidHost is returned when a host[:tls-name][:port] string can not be
+// parsed. Err holds the underlying cause, if any. errors.Is matches any
+// ErrInvalidHost whose non-empty Input and Reason fields are equal.
+type ErrInvalidHost struct {
+	Err    error
+	Input  string
+	Reason string
+}
+
+func (e *ErrInvalidHost) Error() string {
+	msg := fmt.Sprintf("invalid host %q: %s", e.Input, e.Reason)
+
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %s", msg, e.Err)
+	}
+
+	return msg
+}
+
+func (e *ErrInvalidHost) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrInvalidHost) Is(target error) bool {
+	t, ok := target.(*ErrInvalidHost)
+	if !ok {
+		return false
+	}
+
+	return (t.Input == "" || t.Input == e.Input) && (t.Reason == "" || t.Reason == e.Reason)
+}
+
 func NewHostTLSPort() *HostTLSPort {
 	return &HostTLSPort{}
 }
@@ -26,9 +86,63 @@ func NewDefaultHostTLSPort() *HostTLSPort {
 	}
 }
 
+// ParseHostTLSPort parses a host[:tls-name][:port] string as produced by
+// String. IPv6 addresses may be enclosed in brackets, which is required when a
+// tls-name or port follows them. A tls-name made up only of digits is parsed
+// as a port unless a port follows it.
+func ParseHostTLSPort(v string) (*HostTLSPort, error) {
+	if ip := net.ParseIP(v); ip != nil && strings.Contains(v, ":") {
+		return &HostTLSPort{Host: v}, nil
+	}
+
+	for _, regex := range hostTLSPortRegexes {
+		matches := regex.FindStringSubmatch(v)
+		if matches == nil {
+			continue
+		}
+
+		host := &HostTLSPort{}
+
+		for idx, name := range regex.SubexpNames() {
+			switch name {
+			case "host":
+				host.Host = matches[idx]
+			case "tlsName":
+				host.TLSName = matches[idx]
+			case "port":
+				port, err := strconv.Atoi(matches[idx])
+				if err != nil {
+					return nil, &ErrInvalidHost{Input: v, Reason: "failed to parse port", Err: err}
+				}
+
+				if port < minPort || port > maxPort {
+					return nil, &ErrInvalidHost{
+						Input:  v,
+						Reason: fmt.Sprintf("port must be between %d and %d", minPort, maxPort),
+					}
+				}
+
+				host.Port = port
+			}
+		}
+
+		if host.Host == "" {
+			return nil, &ErrInvalidHost{Input: v, Reason: "missing host"}
+		}
+
+		return host, nil
+	}
+
+	return nil, &ErrInvalidHost{Input: v, Reason: "does not match any expected formats"}
+}
+
 func (addr *HostTLSPort) String() string {
 	str := addr.Host
 
+	if strings.Contains(str, ":") {
+		str = fmt.Sprintf("[%s]", str)
+	}
+
 	if addr.TLSName != "" {
 		str = fmt.Sprintf("%s:%s", str, addr.TLSName)
 	}
@@ -42,6 +156,63 @@ func (addr *HostTLSPort) String() string {
 
 type HostTLSPortSlice []*HostTLSPort
 
+// ParseHostTLSPortSlice parses a comma separated list of
+// host[:tls-name][:port] strings. The bracketed form produced by
+// HostTLSPortSlice.String is also accepted. An empty string results in an
+// empty slice.
+func ParseHostTLSPortSlice(commaSepVal string) (HostTLSPortSlice, error) {
+	commaSepVal = strings.TrimSpace(commaSepVal)
+
+	if isBracketedList(commaSepVal) {
+		commaSepVal = strings.TrimSpace(commaSepVal[1 : len(commaSepVal)-1])
+	}
+
+	slice := HostTLSPortSlice{}
+
+	if commaSepVal == "" {
+		return slice, nil
+	}
+
+	for _, val := range strings.Split(commaSepVal, ",") {
+		host, err := ParseHostTLSPort(strings.TrimSpace(val))
+		if err != nil {
+			return nil, err
+		}
+
+		slice = append(slice, host)
+	}
+
+	return slice, nil
+}
+
+// isBracketedList reports whether val is a list wrapped in brackets, e.g.
+// "[host1:3000, [::1]:3000]", rather than starting with a bracketed ipv6
+// address.
+func isBracketedList(val string) bool {
+	if !strings.HasPrefix(val, "[") || !strings.HasSuffix(val, "]") {
+		return false
+	}
+
+	depth := 0
+
+	for i, c := range val {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+
+			if depth == 0 && i != len(val)-1 {
+				return false
+			}
+		}
+	}
+
+	inner := val[1 : len(val)-1]
+
+	return strings.Contains(inner, ",") || strings.TrimSpace(inner) == ""
+}
+
 func (slice *HostTLSPortSlice) String() string {
 	strs := []string{}
 
@@ -1,6 +1,9 @@
 package client
 
 import (
+	"errors"
+	"reflect"
+	"strconv"
 	"testing"
 )
 
@@ -66,3 +69,120 @@ func TestHostTLSPortSliceString(t *testing.T) {
 		t.Errorf("Expected %s, but got %s", expected, result)
 	}
 }
+
+func TestErrInvalidHost(t *testing.T) {
+	var err error = &ErrInvalidHost{Input: "host:port", Reason: "failed to parse port", Err: strconv.ErrSyntax}
+
+	expected := `invalid host "host:port": failed to parse port: invalid syntax`
+	if err.Error() != expected {
+		t.Errorf("Error() = %q, want %q", err.Error(), expected)
+	}
+
+	testCases := []struct {
+		name     string
+		target   error
+		expected bool
+	}{
+		{"Any", &ErrInvalidHost{}, true},
+		{"SameInput", &ErrInvalidHost{Input: "host:port"}, true},
+		{"SameReason", &ErrInvalidHost{Reason: "failed to parse port"}, true},
+		{"OtherInput", &ErrInvalidHost{Input: "other"}, false},
+		{"Cause", strconv.ErrSyntax, true},
+		{"OtherError", strconv.ErrRange, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := errors.Is(err, tc.target); actual != tc.expected {
+				t.Errorf("errors.Is(%v, %v) = %v, want %v", err, tc.target, actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseHostTLSPort(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected *HostTLSPort
+		reason   string
+	}{
+		{"127.0.0.1", &HostTLSPort{Host: "127.0.0.1"}, ""},
+		{"127.0.0.1:3000", &HostTLSPort{Host: "127.0.0.1", Port: 3000}, ""},
+		{"example.com:tls-name", &HostTLSPort{Host: "example.com", TLSName: "tls-name"}, ""},
+		{"example.com:tls-name:3000", &HostTLSPort{Host: "example.com", TLSName: "tls-name", Port: 3000}, ""},
+		{"[::1]", &HostTLSPort{Host: "::1"}, ""},
+		{"[::1]:3000", &HostTLSPort{Host: "::1", Port: 3000}, ""},
+		{"[::1]:tls-name", &HostTLSPort{Host: "::1", TLSName: "tls-name"}, ""},
+		{"[fe80::1]:tls-name:3000", &HostTLSPort{Host: "fe80::1", TLSName: "tls-name", Port: 3000}, ""},
+		{"fe80::1ff:fe23:4567:890a", &HostTLSPort{Host: "fe80::1ff:fe23:4567:890a"}, ""},
+		{"127.0.0.1:0", nil, "port must be between 1 and 65535"},
+		{"127.0.0.1:65536", nil, "port must be between 1 and 65535"},
+		{"127.0.0.1:03000", &HostTLSPort{Host: "127.0.0.1", Port: 3000}, ""},
+		{"[]:3000", nil, "missing host"},
+		{":3000", nil, "does not match any expected formats"},
+		{"127.0.0.1:tls-name:port", nil, "does not match any expected formats"},
+		{"[::1", nil, "does not match any expected formats"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, err := ParseHostTLSPort(tc.input)
+
+			if tc.reason != "" {
+				if !errors.Is(err, &ErrInvalidHost{Input: tc.input, Reason: tc.reason}) {
+					t.Errorf("ParseHostTLSPort() error = %v, want reason %q", err, tc.reason)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseHostTLSPort() returned an unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("ParseHostTLSPort() = %+v, want %+v", actual, tc.expected)
+			}
+
+			roundTrip, err := ParseHostTLSPort(actual.String())
+			if err != nil || !reflect.DeepEqual(roundTrip, actual) {
+				t.Errorf("ParseHostTLSPort(%q) = %+v, %v, want %+v", actual.String(), roundTrip, err, actual)
+			}
+		})
+	}
+}
+
+func TestParseHostTLSPortSlice(t *testing.T) {
+	expected := HostTLSPortSlice{
+		{Host: "127.0.0.1", Port: 3000},
+		{Host: "::1", TLSName: "tls-name", Port: 3000},
+		{Host: "example.com"},
+	}
+
+	testCases := []string{
+		"127.0.0.1:3000,[::1]:tls-name:3000,example.com",
+		"127.0.0.1:3000, [::1]:tls-name:3000, example.com",
+		expected.String(),
+	}
+
+	for _, input := range testCases {
+		t.Run(input, func(t *testing.T) {
+			actual, err := ParseHostTLSPortSlice(input)
+			if err != nil {
+				t.Fatalf("ParseHostTLSPortSlice() returned an unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("ParseHostTLSPortSlice() = %v, want %v", actual.String(), expected.String())
+			}
+		})
+	}
+
+	if actual, err := ParseHostTLSPortSlice(""); err != nil || len(actual) != 0 {
+		t.Errorf("ParseHostTLSPortSlice(\"\") = %v, %v, want an empty slice", actual, err)
+	}
+
+	if _, err := ParseHostTLSPortSlice("127.0.0.1,:3000"); !errors.Is(err, &ErrInvalidHost{Input: ":3000"}) {
+		t.Errorf("ParseHostTLSPortSlice() error = %v, want %v", err, &ErrInvalidHost{Input: ":3000"})
+	}
+}
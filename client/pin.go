@@ -0,0 +1,145 @@
+package client
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const spkiPinPrefix = "sha256/"
+
+var (
+	ErrTLSPinMismatch = fmt.Errorf("server certificate does not match any pin")
+	ErrInvalidTLSPin  = fmt.Errorf("invalid TLS pin")
+)
+
+// TLSPinType is the part of a certificate a TLSPin is a hash of.
+type TLSPinType uint8
+
+const (
+	// TLSPinSPKI pins the SHA-256 hash of the subject public key info, which
+	// stays the same when a certificate is renewed with the same key.
+	TLSPinSPKI TLSPinType = iota
+	// TLSPinCertificate pins the SHA-256 fingerprint of the whole certificate.
+	TLSPinCertificate
+)
+
+// TLSPin is the hash of a server certificate or its public key that the
+// server must present.
+type TLSPin struct {
+	Type TLSPinType
+	Hash [sha256.Size]byte
+}
+
+// NewSPKITLSPin returns the SPKI pin of a certificate.
+func NewSPKITLSPin(cert *x509.Certificate) TLSPin {
+	return TLSPin{Type: TLSPinSPKI, Hash: sha256.Sum256(cert.RawSubjectPublicKeyInfo)}
+}
+
+// NewCertificateTLSPin returns the fingerprint pin of a certificate.
+func NewCertificateTLSPin(cert *x509.Certificate) TLSPin {
+	return TLSPin{Type: TLSPinCertificate, Hash: sha256.Sum256(cert.Raw)}
+}
+
+// ParseTLSPin parses either a "sha256/<base64>" SPKI hash, as used by HPKP and
+// curl's --pinnedpubkey, or a hex SHA-256 certificate fingerprint, optionally
+// colon separated as printed by "openssl x509 -fingerprint -sha256".
+func ParseTLSPin(val string) (TLSPin, error) {
+	pin := TLSPin{}
+
+	var (
+		hash []byte
+		err  error
+	)
+
+	if b64, ok := strings.CutPrefix(val, spkiPinPrefix); ok {
+		pin.Type = TLSPinSPKI
+		hash, err = base64.StdEncoding.DecodeString(b64)
+	} else {
+		pin.Type = TLSPinCertificate
+		hash, err = hex.DecodeString(strings.ReplaceAll(val, ":", ""))
+	}
+
+	if err != nil {
+		return pin, fmt.Errorf("%w %q: %w", ErrInvalidTLSPin, val, err)
+	}
+
+	if len(hash) != sha256.Size {
+		return pin, fmt.Errorf("%w %q: expected a %d byte SHA-256 hash, got %d bytes",
+			ErrInvalidTLSPin, val, sha256.Size, len(hash))
+	}
+
+	copy(pin.Hash[:], hash)
+
+	return pin, nil
+}
+
+// String returns the pin in the format accepted by ParseTLSPin.
+func (p TLSPin) String() string {
+	if p.Type == TLSPinSPKI {
+		return spkiPinPrefix + base64.StdEncoding.EncodeToString(p.Hash[:])
+	}
+
+	octets := make([]string, len(p.Hash))
+
+	for i, b := range p.Hash {
+		octets[i] = fmt.Sprintf("%02X", b)
+	}
+
+	return strings.Join(octets, ":")
+}
+
+// Matches reports whether the certificate matches the pin.
+func (p TLSPin) Matches(cert *x509.Certificate) bool {
+	if p.Type == TLSPinSPKI {
+		return NewSPKITLSPin(cert) == p
+	}
+
+	return NewCertificateTLSPin(cert) == p
+}
+
+// newPinVerifier returns a verifier that requires a certificate in a verified
+// chain to match one of the pins. When CA verification is disabled there are
+// no verified chains and only the server's own certificate is trusted since
+// the rest of the presented chain is unauthenticated.
+func newPinVerifier(pins []TLSPin) peerCertificateVerifier {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(verifiedChains) == 0 {
+			if len(rawCerts) == 0 {
+				return fmt.Errorf("%w: server did not present a certificate", ErrTLSPinMismatch)
+			}
+
+			leaf, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return fmt.Errorf("%w: failed to parse server certificate: %w", ErrTLSPinMismatch, err)
+			}
+
+			verifiedChains = [][]*x509.Certificate{{leaf}}
+		}
+
+		for _, chain := range verifiedChains {
+			for _, cert := range chain {
+				for _, pin := range pins {
+					if pin.Matches(cert) {
+						return nil
+					}
+				}
+			}
+		}
+
+		leaf := verifiedChains[0][0]
+		pinStrs := make([]string, len(pins))
+
+		for i, pin := range pins {
+			pinStrs[i] = pin.String()
+		}
+
+		return fmt.Errorf(
+			"%w: server certificate %q with SPKI pin %s and fingerprint %s failed pins %s",
+			ErrTLSPinMismatch, leaf.Subject, NewSPKITLSPin(leaf), NewCertificateTLSPin(leaf), strings.Join(pinStrs, ", "),
+		)
+	}
+}
@@ -0,0 +1,214 @@
+package client
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aerospike/tools-common-go/internal/tlstest"
+)
+
+func parsePEMCert(t *testing.T, certPEM []byte) *x509.Certificate {
+	t.Helper()
+
+	block, _ := pem.Decode(certPEM)
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	return cert
+}
+
+func TestParseTLSPin(t *testing.T) {
+	certPEM, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(20))
+	cert := parsePEMCert(t, certPEM)
+	spkiPin := NewSPKITLSPin(cert)
+	certPin := NewCertificateTLSPin(cert)
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected TLSPin
+		wantErr  bool
+	}{
+		{
+			name:     "SPKI",
+			input:    spkiPin.String(),
+			expected: spkiPin,
+		},
+		{
+			name:     "ColonSeparatedFingerprint",
+			input:    certPin.String(),
+			expected: certPin,
+		},
+		{
+			name:     "LowercaseFingerprint",
+			input:    strings.ToLower(strings.ReplaceAll(certPin.String(), ":", "")),
+			expected: certPin,
+		},
+		{
+			name:    "InvalidBase64",
+			input:   "sha256/not base64",
+			wantErr: true,
+		},
+		{
+			name:    "ShortSPKI",
+			input:   "sha256/AAAA",
+			wantErr: true,
+		},
+		{
+			name:    "ShortFingerprint",
+			input:   "AB:CD",
+			wantErr: true,
+		},
+		{
+			name:    "InvalidHex",
+			input:   "not a pin",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := ParseTLSPin(tc.input)
+
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidTLSPin) {
+					t.Errorf("ParseTLSPin() error = %v, want %v", err, ErrInvalidTLSPin)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseTLSPin() returned an unexpected error: %v", err)
+			}
+
+			if actual != tc.expected {
+				t.Errorf("ParseTLSPin() = %v, want %v", actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestNewGoTLSConfigPins(t *testing.T) {
+	rootCA, _ := tlstest.GenerateCert()
+	certPEM, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(21))
+	otherPEM, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(22))
+	cert := parsePEMCert(t, certPEM)
+	caPin := NewSPKITLSPin(parsePEMCert(t, rootCA))
+	otherPin := NewCertificateTLSPin(parsePEMCert(t, otherPEM))
+
+	testCases := []struct {
+		name     string
+		rootCA   [][]byte
+		pins     []TLSPin
+		expected error
+		pinOnly  bool
+	}{
+		{
+			name:    "SPKIPinOnly",
+			pins:    []TLSPin{otherPin, NewSPKITLSPin(cert)},
+			pinOnly: true,
+		},
+		{
+			name:    "FingerprintPinOnly",
+			pins:    []TLSPin{NewCertificateTLSPin(cert)},
+			pinOnly: true,
+		},
+		{
+			name:   "CAPinWithCA",
+			rootCA: [][]byte{rootCA},
+			pins:   []TLSPin{caPin},
+		},
+		{
+			// The presented CA certificate is not authenticated without CA
+			// verification so it can not satisfy a pin.
+			name:     "CAPinPinOnly",
+			pins:     []TLSPin{caPin},
+			expected: ErrTLSPinMismatch,
+			pinOnly:  true,
+		},
+		{
+			name:     "Mismatch",
+			rootCA:   [][]byte{rootCA},
+			pins:     []TLSPin{otherPin},
+			expected: ErrTLSPinMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tlsConf := &TLSConfig{
+				RootCA:                 tc.rootCA,
+				Pins:                   tc.pins,
+				PinOnly:                tc.pinOnly,
+				TLSProtocolsMinVersion: VersionTLSDefaultMin,
+				TLSProtocolsMaxVersion: VersionTLSDefaultMax,
+			}
+
+			tlsConfig, err := tlsConf.NewGoTLSConfig()
+			if err != nil {
+				t.Fatalf("NewGoTLSConfig() returned an unexpected error: %v", err)
+			}
+
+			err = handshake(t, tlsConfig, append(append([]byte{}, certPEM...), rootCA...), nil)
+
+			if tc.expected == nil {
+				if err != nil {
+					t.Errorf("handshake returned an unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("handshake error = %v, want %v", err, tc.expected)
+			}
+
+			for _, pin := range tc.pins {
+				if !strings.Contains(err.Error(), pin.String()) {
+					t.Errorf("handshake error %q does not name pin %s", err, pin)
+				}
+			}
+		})
+	}
+}
+
+func TestNewGoTLSConfigPinsWithoutCA(t *testing.T) {
+	certPEM, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(23))
+	pin := NewSPKITLSPin(parsePEMCert(t, certPEM))
+
+	// Pins alone do not replace CA verification unless PinOnly is set.
+	tlsConf := &TLSConfig{
+		Pins:                   []TLSPin{pin},
+		TLSProtocolsMinVersion: VersionTLSDefaultMin,
+		TLSProtocolsMaxVersion: VersionTLSDefaultMax,
+	}
+
+	tlsConfig, err := tlsConf.NewGoTLSConfig()
+	if err != nil {
+		t.Fatalf("NewGoTLSConfig() returned an unexpected error: %v", err)
+	}
+
+	if tlsConfig.InsecureSkipVerify {
+		t.Errorf("NewGoTLSConfig() skips CA verification without PinOnly")
+	}
+
+	var unknownAuthority x509.UnknownAuthorityError
+
+	if err := handshake(t, tlsConfig, certPEM, nil); !errors.As(err, &unknownAuthority) {
+		t.Errorf("handshake error = %v, want %T", err, unknownAuthority)
+	}
+
+	tlsConf.Pins = nil
+	tlsConf.PinOnly = true
+
+	if _, err := tlsConf.NewGoTLSConfig(); err == nil {
+		t.Errorf("NewGoTLSConfig() should fail with PinOnly and no pins")
+	}
+}
@@ -0,0 +1,247 @@
+package client
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des" //nolint:gosec // 3DES is still used by older PKI tooling to encrypt keys
+	"crypto/pbkdf2"
+	"crypto/sha1" //nolint:gosec // hmacWithSHA1 is the PBKDF2 default PRF
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"hash"
+)
+
+// ErrPKCS8IncorrectPassword is returned when an encrypted PKCS#8 private key
+// cannot be decrypted with the provided password.
+var ErrPKCS8IncorrectPassword = fmt.Errorf("pkcs8: decryption password incorrect")
+
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA224 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 8}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidAES128GCM  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 6}
+	oidAES192GCM  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 26}
+	oidAES256GCM  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 46}
+)
+
+// encryptedPrivateKeyInfo is defined in RFC 5208 section 6.
+type encryptedPrivateKeyInfo struct {
+	Algorithm     algorithmIdentifier
+	EncryptedData []byte
+}
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+// pbes2Params is defined in RFC 8018 appendix A.4.
+type pbes2Params struct {
+	KeyDerivationFunc algorithmIdentifier
+	EncryptionScheme  algorithmIdentifier
+}
+
+// pbkdf2Params is defined in RFC 8018 appendix A.2.
+type pbkdf2Params struct { //nolint:govet // field order is defined by the ASN.1 structure
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                 `asn1:"optional"`
+	PRF            algorithmIdentifier `asn1:"optional"`
+}
+
+// gcmParams is defined in RFC 5084 section 3.2.
+type gcmParams struct {
+	Nonce  []byte
+	ICVLen int `asn1:"default:12"`
+}
+
+// pkcs8Cipher describes a PBES2 encryption scheme.
+type pkcs8Cipher struct {
+	block  func(key []byte) (cipher.Block, error)
+	keyLen int
+	gcm    bool
+}
+
+var pkcs8Ciphers = map[string]pkcs8Cipher{
+	oidDESEDE3CBC.String(): {des.NewTripleDESCipher, 24, false},
+	oidAES128CBC.String():  {aes.NewCipher, 16, false},
+	oidAES192CBC.String():  {aes.NewCipher, 24, false},
+	oidAES256CBC.String():  {aes.NewCipher, 32, false},
+	oidAES128GCM.String():  {aes.NewCipher, 16, true},
+	oidAES192GCM.String():  {aes.NewCipher, 24, true},
+	oidAES256GCM.String():  {aes.NewCipher, 32, true},
+}
+
+var pkcs8PRFs = map[string]func() hash.Hash{
+	oidHMACWithSHA1.String():   sha1.New,
+	oidHMACWithSHA224.String(): sha256.New224,
+	oidHMACWithSHA256.String(): sha256.New,
+	oidHMACWithSHA384.String(): sha512.New384,
+	oidHMACWithSHA512.String(): sha512.New,
+}
+
+// DecryptPKCS8PrivateKey decrypts the DER bytes of a PEM "ENCRYPTED PRIVATE
+// KEY" block and returns the DER bytes of the unencrypted PKCS#8 private key.
+// Only PBES2 with PBKDF2 is supported, with AES-CBC, AES-GCM or 3DES-CBC
+// encryption, which covers keys produced by OpenSSL 1.1 and later.
+func DecryptPKCS8PrivateKey(der, password []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("pkcs8: failed to parse encrypted private key: %w", err)
+	}
+
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf(
+			"pkcs8: unsupported encryption algorithm %s, only PBES2 is supported", info.Algorithm.Algorithm,
+		)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("pkcs8: failed to parse PBES2 parameters: %w", err)
+	}
+
+	c, ok := pkcs8Ciphers[params.EncryptionScheme.Algorithm.String()]
+	if !ok {
+		return nil, fmt.Errorf("pkcs8: unsupported encryption scheme %s", params.EncryptionScheme.Algorithm)
+	}
+
+	key, err := pkcs8DeriveKey(params.KeyDerivationFunc, password, c.keyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := c.block(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var decrypted []byte
+
+	if c.gcm {
+		decrypted, err = pkcs8DecryptGCM(block, params.EncryptionScheme.Parameters.FullBytes, info.EncryptedData)
+	} else {
+		decrypted, err = pkcs8DecryptCBC(block, params.EncryptionScheme.Parameters.FullBytes, info.EncryptedData)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// A wrong password may still produce valid padding so make sure the
+	// result is a private key.
+	if _, err := x509.ParsePKCS8PrivateKey(decrypted); err != nil {
+		return nil, ErrPKCS8IncorrectPassword
+	}
+
+	return decrypted, nil
+}
+
+func pkcs8DeriveKey(kdf algorithmIdentifier, password []byte, keyLen int) ([]byte, error) {
+	if !kdf.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("pkcs8: unsupported key derivation function %s, only PBKDF2 is supported", kdf.Algorithm)
+	}
+
+	var params pbkdf2Params
+	if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("pkcs8: failed to parse PBKDF2 parameters: %w", err)
+	}
+
+	prf := sha1.New
+
+	if len(params.PRF.Algorithm) != 0 {
+		var ok bool
+
+		prf, ok = pkcs8PRFs[params.PRF.Algorithm.String()]
+		if !ok {
+			return nil, fmt.Errorf("pkcs8: unsupported PBKDF2 PRF %s", params.PRF.Algorithm)
+		}
+	}
+
+	if params.KeyLength != 0 && params.KeyLength != keyLen {
+		return nil, fmt.Errorf(
+			"pkcs8: PBKDF2 key length %d does not match the cipher key length %d", params.KeyLength, keyLen,
+		)
+	}
+
+	return pbkdf2.Key(prf, string(password), params.Salt, params.IterationCount, keyLen)
+}
+
+func pkcs8DecryptCBC(block cipher.Block, params, encrypted []byte) ([]byte, error) {
+	var iv []byte
+	if _, err := asn1.Unmarshal(params, &iv); err != nil {
+		return nil, fmt.Errorf("pkcs8: failed to parse IV: %w", err)
+	}
+
+	if len(iv) != block.BlockSize() {
+		return nil, fmt.Errorf("pkcs8: invalid IV length %d", len(iv))
+	}
+
+	if len(encrypted) == 0 || len(encrypted)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("pkcs8: encrypted data is not a multiple of the block size")
+	}
+
+	decrypted := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, encrypted)
+
+	// Remove PKCS#7 padding.
+	padLen := int(decrypted[len(decrypted)-1])
+	if padLen == 0 || padLen > block.BlockSize() {
+		return nil, ErrPKCS8IncorrectPassword
+	}
+
+	for _, b := range decrypted[len(decrypted)-padLen:] {
+		if int(b) != padLen {
+			return nil, ErrPKCS8IncorrectPassword
+		}
+	}
+
+	return decrypted[:len(decrypted)-padLen], nil
+}
+
+func pkcs8DecryptGCM(block cipher.Block, params, encrypted []byte) ([]byte, error) {
+	var p gcmParams
+	if _, err := asn1.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("pkcs8: failed to parse GCM parameters: %w", err)
+	}
+
+	var (
+		aead cipher.AEAD
+		err  error
+	)
+
+	// crypto/cipher supports either a non-standard nonce size or a
+	// non-standard tag size but not both.
+	switch {
+	case len(p.Nonce) == 12:
+		aead, err = cipher.NewGCMWithTagSize(block, p.ICVLen)
+	case p.ICVLen == 16:
+		aead, err = cipher.NewGCMWithNonceSize(block, len(p.Nonce))
+	default:
+		err = fmt.Errorf("nonce size %d with tag size %d", len(p.Nonce), p.ICVLen)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("pkcs8: unsupported GCM parameters: %w", err)
+	}
+
+	decrypted, err := aead.Open(nil, p.Nonce, encrypted, nil)
+	if err != nil {
+		return nil, ErrPKCS8IncorrectPassword
+	}
+
+	return decrypted, nil
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/aerospike/tools-common-go/internal/tlstest"
+)
+
+func TestDecryptPKCS8PrivateKey(t *testing.T) {
+	password := []byte("fakepassphrase")
+
+	testCases := []struct {
+		name   string
+		cipher tlstest.PKCS8Cipher
+	}{
+		{"AES128CBC", tlstest.PKCS8AES128CBC},
+		{"AES256CBC", tlstest.PKCS8AES256CBC},
+		{"AES256GCM", tlstest.PKCS8AES256GCM},
+		{"DESEDE3CBC", tlstest.PKCS8DESEDE3CBC},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			keyPEM, err := tlstest.EncryptPKCS8PrivateKey(tlstest.CAKey, password, tc.cipher)
+			if err != nil {
+				t.Fatalf("EncryptPKCS8PrivateKey() returned an unexpected error: %v", err)
+			}
+
+			block, _ := pem.Decode(keyPEM)
+
+			der, err := DecryptPKCS8PrivateKey(block.Bytes, password)
+			if err != nil {
+				t.Fatalf("DecryptPKCS8PrivateKey() returned an unexpected error: %v", err)
+			}
+
+			key, err := x509.ParsePKCS8PrivateKey(der)
+			if err != nil {
+				t.Fatalf("DecryptPKCS8PrivateKey() returned an invalid key: %v", err)
+			}
+
+			if !tlstest.CAKey.Equal(key) {
+				t.Errorf("DecryptPKCS8PrivateKey() returned a different key")
+			}
+
+			_, err = DecryptPKCS8PrivateKey(block.Bytes, []byte("wrongpassphrase"))
+			if !errors.Is(err, ErrPKCS8IncorrectPassword) {
+				t.Errorf("DecryptPKCS8PrivateKey() error = %v, want %v", err, ErrPKCS8IncorrectPassword)
+			}
+		})
+	}
+}
+
+// The testdata key was generated with
+// "openssl pkcs8 -topk8 -v2 aes-256-cbc -v2prf hmacWithSHA512".
+func TestLoadServerCertAndKeyOpenSSLPKCS8(t *testing.T) {
+	certBytes, err := os.ReadFile("testdata/pkcs8_cert.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyBytes, err := os.ReadFile("testdata/pkcs8_encrypted_key.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certs, err := LoadServerCertAndKey(certBytes, keyBytes, []byte("test-pass"))
+	if err != nil {
+		t.Fatalf("LoadServerCertAndKey() returned an unexpected error: %v", err)
+	}
+
+	if len(certs) != 1 {
+		t.Fatalf("LoadServerCertAndKey() returned %d certificates, want 1", len(certs))
+	}
+
+	_, err = LoadServerCertAndKey(certBytes, keyBytes, nil)
+	if !errors.Is(err, ErrPKCS8IncorrectPassword) {
+		t.Errorf("LoadServerCertAndKey() error = %v, want %v", err, ErrPKCS8IncorrectPassword)
+	}
+}
+
+func TestDecryptPKCS8PrivateKeyUnsupported(t *testing.T) {
+	if _, err := DecryptPKCS8PrivateKey([]byte("garbage"), nil); err == nil {
+		t.Errorf("DecryptPKCS8PrivateKey() should fail on invalid input")
+	}
+
+	// An unencrypted PKCS#8 key has a different structure.
+	der, _ := x509.MarshalPKCS8PrivateKey(tlstest.CAKey)
+	if _, err := DecryptPKCS8PrivateKey(der, nil); err == nil {
+		t.Errorf("DecryptPKCS8PrivateKey() should fail on an unencrypted key")
+	}
+}
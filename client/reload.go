@@ -0,0 +1,516 @@
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultTLSReloadDebounce is how long a TLSReloader waits after the last
+// file change before reloading.
+const DefaultTLSReloadDebounce = 100 * time.Millisecond
+
+var (
+	ErrNoTLSFiles        = fmt.Errorf("no TLS files to watch")
+	ErrNoTLSServerName   = fmt.Errorf("a TLS server name is required to verify the server certificate")
+	ErrNoPeerCertificate = fmt.Errorf("server did not present a certificate")
+)
+
+// TLSFiles are the paths the certificates and keys of a TLSConfig were read
+// from. They allow a TLSReloader to pick up renewed certificates.
+type TLSFiles struct {
+	RootCAFile string `json:"root-ca-file,omitempty" yaml:"root-ca-file,omitempty"`
+	// RootCAPath is a directory in which every file is a CA certificate.
+	// Hidden entries, such as the "..data" link of a Kubernetes secret
+	// volume, are skipped.
+	RootCAPath string `json:"root-ca-path,omitempty" yaml:"root-ca-path,omitempty"`
+	CertFile   string `json:"cert-file,omitempty" yaml:"cert-file,omitempty"`
+	KeyFile    string `json:"key-file,omitempty" yaml:"key-file,omitempty"`
+	PKCS12File string `json:"pkcs12-file,omitempty" yaml:"pkcs12-file,omitempty"`
+	CRLFile    string `json:"crl-file,omitempty" yaml:"crl-file,omitempty"`
+	// CRLPath is a directory in which every file is a certificate revocation
+	// list. Hidden entries are skipped as for RootCAPath.
+	CRLPath string `json:"crl-path,omitempty" yaml:"crl-path,omitempty"`
+}
+
+// files returns the files that are read directly.
+func (f *TLSFiles) files() []string {
+	return []string{f.RootCAFile, f.CertFile, f.KeyFile, f.PKCS12File, f.CRLFile}
+}
+
+// read returns the contents of every file keyed by path.
+func (f *TLSFiles) read() (map[string][]byte, error) {
+	contents := map[string][]byte{}
+
+	files := slices.DeleteFunc(f.files(), func(file string) bool { return file == "" })
+
+	for _, dir := range []string{f.RootCAPath, f.CRLPath} {
+		dirFiles, err := pathFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, dirFiles...)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS file: %w", err)
+		}
+
+		contents[file] = data
+	}
+
+	return contents, nil
+}
+
+// pathFiles returns the sorted regular files in dir, skipping hidden entries.
+func pathFiles(dir string) ([]string, error) {
+	if dir == "" {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS path: %w", err)
+	}
+
+	files := []string{}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		file := filepath.Join(dir, entry.Name())
+
+		// Stat follows symlinks which secret volumes use for every file.
+		info, err := os.Stat(file)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// dirs returns the directories to watch. Parent directories are watched
+// rather than the files so that files replaced by a rename are noticed.
+func (f *TLSFiles) dirs() []string {
+	dirs := []string{}
+
+	for _, file := range f.files() {
+		if file != "" {
+			dirs = append(dirs, filepath.Dir(file))
+		}
+	}
+
+	for _, dir := range []string{f.RootCAPath, f.CRLPath} {
+		if dir != "" {
+			dirs = append(dirs, filepath.Clean(dir))
+		}
+	}
+
+	slices.Sort(dirs)
+
+	return slices.Compact(dirs)
+}
+
+// apply returns a copy of tc with its certificates and keys replaced by the
+// file contents.
+func (f *TLSFiles) apply(tc TLSConfig, contents map[string][]byte) (TLSConfig, error) {
+	if f.RootCAFile != "" || f.RootCAPath != "" {
+		tc.RootCA = [][]byte{}
+
+		if f.RootCAFile != "" {
+			tc.RootCA = append(tc.RootCA, contents[f.RootCAFile])
+		}
+
+		caFiles, err := pathFiles(f.RootCAPath)
+		if err != nil {
+			return tc, err
+		}
+
+		for _, file := range caFiles {
+			if data, ok := contents[file]; ok {
+				tc.RootCA = append(tc.RootCA, data)
+			}
+		}
+	}
+
+	if f.CertFile != "" {
+		tc.Cert = contents[f.CertFile]
+	}
+
+	if f.KeyFile != "" {
+		tc.Key = contents[f.KeyFile]
+	}
+
+	if f.PKCS12File != "" {
+		tc.PKCS12 = contents[f.PKCS12File]
+	}
+
+	if f.CRLFile != "" || f.CRLPath != "" {
+		tc.CRLs = [][]byte{}
+
+		if f.CRLFile != "" {
+			tc.CRLs = append(tc.CRLs, contents[f.CRLFile])
+		}
+
+		crlFiles, err := pathFiles(f.CRLPath)
+		if err != nil {
+			return tc, err
+		}
+
+		for _, file := range crlFiles {
+			if data, ok := contents[file]; ok {
+				tc.CRLs = append(tc.CRLs, data)
+			}
+		}
+	}
+
+	return tc, nil
+}
+
+// TLSReloadEvent reports the outcome of a reload.
+type TLSReloadEvent struct {
+	// Err is set when the files could not be loaded or watched. The
+	// previously loaded certificates remain in use.
+	Err error
+	// Changed lists the files whose contents changed.
+	Changed []string
+}
+
+// TLSReloadOptions configures a TLSReloader.
+type TLSReloadOptions struct {
+	// OnReload is called after every reload of changed files and for watcher
+	// errors. It is called from the watching goroutine and should not block.
+	OnReload func(TLSReloadEvent)
+	// Debounce is how long to wait after the last file change before
+	// reloading, so that a certificate and key written one after the other
+	// are loaded together. Defaults to DefaultTLSReloadDebounce.
+	Debounce time.Duration
+}
+
+// TLSReloader watches the files of a TLSConfig and serves the most recently
+// loaded client certificate and CA certificates to new handshakes, allowing
+// long running tools to pick up renewed certificates without restarting.
+// Use GoTLSConfig in place of TLSConfig.NewGoTLSConfig and call Close when
+// done.
+type TLSReloader struct {
+	config   *tls.Config
+	watcher  *fsnotify.Watcher
+	done     chan struct{}
+	contents map[string][]byte
+	cert     *tls.Certificate
+	rootCAs  *x509.CertPool
+	// verifyPeerCertificate and verifyConnection are the revocation checks
+	// of the most recently loaded files, e.g. their CRLs.
+	verifyPeerCertificate func([][]byte, [][]*x509.Certificate) error
+	verifyConnection      func(tls.ConnectionState) error
+	opts                  TLSReloadOptions
+	tc                    TLSConfig
+	wg                    sync.WaitGroup
+	mu                    sync.RWMutex
+	closeOnce             sync.Once
+	reloadMu              sync.Mutex
+}
+
+// NewTLSReloader loads the files listed in tc.Files, falling back to the
+// contents of tc for anything without a file, and starts watching them.
+func NewTLSReloader(tc *TLSConfig, opts TLSReloadOptions) (*TLSReloader, error) {
+	if tc.Files == (TLSFiles{}) {
+		return nil, ErrNoTLSFiles
+	}
+
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultTLSReloadDebounce
+	}
+
+	r := &TLSReloader{
+		tc:   *tc,
+		opts: opts,
+		done: make(chan struct{}),
+	}
+
+	base, _, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	r.config = r.newGoTLSConfig(base)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch TLS files: %w", err)
+	}
+
+	for _, dir := range r.tc.Files.dirs() {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("failed to watch TLS files in %s: %w", dir, err)
+		}
+	}
+
+	r.watcher = watcher
+
+	r.wg.Add(1)
+
+	go r.watch()
+
+	return r, nil
+}
+
+// GoTLSConfig returns a tls.Config that presents the most recently loaded
+// client certificate and verifies servers against the most recently loaded
+// CA certificates. Servers are verified against the TLS name sent using SNI
+// so the TLS name must be a host name rather than an IP address.
+func (r *TLSReloader) GoTLSConfig() *tls.Config {
+	return r.config
+}
+
+// Reload re-reads the watched files and swaps in the new certificates if any
+// of them changed. It is called automatically when the files change but may
+// also be called directly, e.g. on SIGHUP. On failure the previously loaded
+// certificates remain in use.
+func (r *TLSReloader) Reload() error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	_, changed, err := r.load()
+	if len(changed) > 0 || err != nil {
+		r.notify(TLSReloadEvent{Changed: changed, Err: err})
+	}
+
+	return err
+}
+
+// Close stops watching the files. Connections established with GoTLSConfig
+// keep working and new handshakes use the last loaded certificates.
+func (r *TLSReloader) Close() error {
+	var err error
+
+	r.closeOnce.Do(func() {
+		close(r.done)
+		err = r.watcher.Close()
+		r.wg.Wait()
+	})
+
+	return err
+}
+
+// load reads the files and, if any of them changed, builds a new client
+// certificate, CA pool and revocation checks from them. It returns the tls.Config built from the
+// new files and the names of the changed files.
+func (r *TLSReloader) load() (*tls.Config, []string, error) {
+	contents, err := r.tc.Files.read()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.mu.RLock()
+	changed := changedFiles(r.contents, contents)
+	r.mu.RUnlock()
+
+	if len(changed) == 0 && r.contents != nil {
+		return nil, nil, nil
+	}
+
+	tc, err := r.tc.Files.apply(r.tc, contents)
+	if err != nil {
+		return nil, changed, err
+	}
+
+	tlsConfig, err := tc.NewGoTLSConfig()
+	if err != nil {
+		return nil, changed, err
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{RootCAs: LoadCACerts(nil)} //nolint:gosec // only used for its RootCAs
+	}
+
+	var cert *tls.Certificate
+
+	if len(tlsConfig.Certificates) > 0 {
+		cert = &tlsConfig.Certificates[0]
+	}
+
+	r.mu.Lock()
+	r.contents = contents
+	r.cert = cert
+	r.rootCAs = tlsConfig.RootCAs
+	r.verifyPeerCertificate = tlsConfig.VerifyPeerCertificate
+	r.verifyConnection = tlsConfig.VerifyConnection
+	r.mu.Unlock()
+
+	return tlsConfig, changed, nil
+}
+
+func (r *TLSReloader) watch() {
+	defer r.wg.Done()
+
+	timer := time.NewTimer(r.opts.Debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			timer.Stop()
+			return
+		case _, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+
+			// Any event in a watched directory may replace one of our files,
+			// e.g. Kubernetes swaps a "..data" symlink, so reload and let the
+			// contents decide.
+			timer.Reset(r.opts.Debounce)
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+
+			r.notify(TLSReloadEvent{Err: fmt.Errorf("failed to watch TLS files: %w", err)})
+		case <-timer.C:
+			_ = r.Reload()
+		}
+	}
+}
+
+func (r *TLSReloader) notify(event TLSReloadEvent) {
+	if r.opts.OnReload != nil {
+		r.opts.OnReload(event)
+	}
+}
+
+// newGoTLSConfig returns a copy of base that takes its client certificate, CA
+// pool and revocation checks from the reloader. Go only verifies servers
+// against a fixed RootCAs pool so verification is done in VerifyConnection
+// instead, followed by the revocation checks of the last loaded files.
+func (r *TLSReloader) newGoTLSConfig(base *tls.Config) *tls.Config {
+	tlsConfig := base.Clone()
+	pinsOnly := tlsConfig.InsecureSkipVerify
+
+	tlsConfig.Certificates = nil
+	tlsConfig.RootCAs = nil
+	tlsConfig.GetClientCertificate = r.getClientCertificate
+	tlsConfig.InsecureSkipVerify = true //nolint:gosec // the server certificate is verified in VerifyConnection
+	tlsConfig.VerifyPeerCertificate = nil
+	tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+		var chains [][]*x509.Certificate
+
+		r.mu.RLock()
+		verifyPeerCertificate := r.verifyPeerCertificate
+		verifyConnection := r.verifyConnection
+		r.mu.RUnlock()
+
+		// When only pins are configured they are checked below instead.
+		if !pinsOnly {
+			var err error
+
+			chains, err = r.verifyServerCertificate(cs)
+			if err != nil {
+				return err
+			}
+		}
+
+		if verifyPeerCertificate != nil {
+			rawCerts := make([][]byte, len(cs.PeerCertificates))
+
+			for i, cert := range cs.PeerCertificates {
+				rawCerts[i] = cert.Raw
+			}
+
+			if err := verifyPeerCertificate(rawCerts, chains); err != nil {
+				return err
+			}
+		}
+
+		if verifyConnection != nil {
+			cs.VerifiedChains = chains
+			return verifyConnection(cs)
+		}
+
+		return nil
+	}
+
+	return tlsConfig
+}
+
+func (r *TLSReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.cert == nil {
+		// An empty certificate tells the server we have none.
+		return &tls.Certificate{}, nil
+	}
+
+	return r.cert, nil
+}
+
+func (r *TLSReloader) verifyServerCertificate(cs tls.ConnectionState) ([][]*x509.Certificate, error) {
+	if len(cs.PeerCertificates) == 0 {
+		return nil, ErrNoPeerCertificate
+	}
+
+	if cs.ServerName == "" {
+		return nil, ErrNoTLSServerName
+	}
+
+	r.mu.RLock()
+	rootCAs := r.rootCAs
+	r.mu.RUnlock()
+
+	opts := x509.VerifyOptions{
+		Roots:         rootCAs,
+		DNSName:       cs.ServerName,
+		Intermediates: x509.NewCertPool(),
+	}
+
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	chains, err := cs.PeerCertificates[0].Verify(opts)
+	if err != nil {
+		return nil, &tls.CertificateVerificationError{UnverifiedCertificates: cs.PeerCertificates, Err: err}
+	}
+
+	return chains, nil
+}
+
+// changedFiles returns the sorted paths that were added, removed or modified.
+func changedFiles(before, after map[string][]byte) []string {
+	changed := []string{}
+
+	for file, data := range after {
+		if old, ok := before[file]; !ok || !bytes.Equal(old, data) {
+			changed = append(changed, file)
+		}
+	}
+
+	for file := range before {
+		if _, ok := after[file]; !ok {
+			changed = append(changed, file)
+		}
+	}
+
+	slices.Sort(changed)
+
+	return changed
+}
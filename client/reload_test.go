@@ -0,0 +1,315 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aerospike/tools-common-go/internal/tlstest"
+)
+
+// mutualHandshake performs a TLS handshake between a client using clientConf
+// and a server signed by the test CA that requests a client certificate. It
+// returns the certificate the client presented.
+func mutualHandshake(t *testing.T, clientConf *tls.Config) (*x509.Certificate, error) {
+	t.Helper()
+
+	certPEM, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(2))
+
+	serverCert, err := tls.X509KeyPair(certPEM, tlstest.LeafKeyFileBytes)
+	if err != nil {
+		t.Fatalf("failed to load server certificate: %v", err)
+	}
+
+	clientConn, serverConn := net.Pipe()
+	clientCerts := make(chan []*x509.Certificate, 1)
+
+	go func() {
+		server := tls.Server(serverConn, &tls.Config{ //nolint:gosec // test server
+			Certificates: []tls.Certificate{serverCert},
+			ClientAuth:   tls.RequireAnyClientCert,
+		})
+		_ = server.Handshake()
+
+		clientCerts <- server.ConnectionState().PeerCertificates
+
+		serverConn.Close()
+	}()
+
+	clientConf = clientConf.Clone()
+	clientConf.ServerName = "localhost"
+	client := tls.Client(clientConn, clientConf)
+	err = client.Handshake()
+
+	clientConn.Close()
+
+	certs := <-clientCerts
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(certs) == 0 {
+		return nil, nil
+	}
+
+	return certs[0], nil
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func waitForReload(t *testing.T, events chan TLSReloadEvent, file string) TLSReloadEvent {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case event := <-events:
+			if event.Err != nil || slices.Contains(event.Changed, file) {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s to be reloaded", file)
+		}
+	}
+}
+
+func newTestTLSReloader(t *testing.T, files TLSFiles) (*TLSReloader, chan TLSReloadEvent) {
+	t.Helper()
+
+	events := make(chan TLSReloadEvent, 10)
+	tc := &TLSConfig{
+		TLSProtocolsMinVersion: VersionTLSDefaultMin,
+		TLSProtocolsMaxVersion: VersionTLSDefaultMax,
+		Files:                  files,
+	}
+
+	reloader, err := NewTLSReloader(tc, TLSReloadOptions{
+		OnReload: func(event TLSReloadEvent) { events <- event },
+		Debounce: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewTLSReloader() returned an unexpected error: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := reloader.Close(); err != nil {
+			t.Errorf("Close() returned an unexpected error: %v", err)
+		}
+	})
+
+	return reloader, events
+}
+
+func TestTLSReloaderClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	rootCA, _ := tlstest.GenerateCert()
+	cert1, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(10))
+	cert2, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(11))
+	files := TLSFiles{
+		RootCAFile: filepath.Join(dir, "ca.pem"),
+		CertFile:   filepath.Join(dir, "cert.pem"),
+		KeyFile:    filepath.Join(dir, "key.pem"),
+	}
+
+	writeFile(t, files.RootCAFile, rootCA)
+	writeFile(t, files.CertFile, cert1)
+	writeFile(t, files.KeyFile, tlstest.LeafKeyFileBytes)
+
+	reloader, events := newTestTLSReloader(t, files)
+
+	clientCert, err := mutualHandshake(t, reloader.GoTLSConfig())
+	if err != nil {
+		t.Fatalf("handshake returned an unexpected error: %v", err)
+	}
+
+	if clientCert.SerialNumber.Int64() != 10 {
+		t.Errorf("client presented serial %s, want 10", clientCert.SerialNumber)
+	}
+
+	writeFile(t, files.CertFile, cert2)
+
+	if event := waitForReload(t, events, files.CertFile); event.Err != nil {
+		t.Fatalf("reload returned an unexpected error: %v", event.Err)
+	}
+
+	clientCert, err = mutualHandshake(t, reloader.GoTLSConfig())
+	if err != nil {
+		t.Fatalf("handshake returned an unexpected error: %v", err)
+	}
+
+	if clientCert.SerialNumber.Int64() != 11 {
+		t.Errorf("client presented serial %s, want 11", clientCert.SerialNumber)
+	}
+
+	// A broken key is reported and the previous certificate stays in use.
+	writeFile(t, files.KeyFile, []byte("not a key"))
+
+	if event := waitForReload(t, events, files.KeyFile); event.Err == nil {
+		t.Errorf("reload of an invalid key should report an error")
+	}
+
+	clientCert, err = mutualHandshake(t, reloader.GoTLSConfig())
+	if err != nil {
+		t.Fatalf("handshake returned an unexpected error: %v", err)
+	}
+
+	if clientCert.SerialNumber.Int64() != 11 {
+		t.Errorf("client presented serial %s, want 11", clientCert.SerialNumber)
+	}
+}
+
+func TestTLSReloaderRootCAs(t *testing.T) {
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "cas")
+	caFile := filepath.Join(caPath, "ca.pem")
+	rootCA, _ := tlstest.GenerateCert()
+	untrustedCA, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(30))
+
+	if err := os.Mkdir(caPath, 0o700); err != nil {
+		t.Fatalf("failed to create %s: %v", caPath, err)
+	}
+
+	writeFile(t, caFile, untrustedCA)
+
+	reloader, events := newTestTLSReloader(t, TLSFiles{RootCAPath: caPath})
+
+	var verificationErr *tls.CertificateVerificationError
+
+	if _, err := mutualHandshake(t, reloader.GoTLSConfig()); !errors.As(err, &verificationErr) {
+		t.Errorf("handshake with an untrusted CA error = %v, want %T", err, verificationErr)
+	}
+
+	writeFile(t, caFile, rootCA)
+
+	if event := waitForReload(t, events, caFile); event.Err != nil {
+		t.Fatalf("reload returned an unexpected error: %v", event.Err)
+	}
+
+	clientCert, err := mutualHandshake(t, reloader.GoTLSConfig())
+	if err != nil {
+		t.Fatalf("handshake returned an unexpected error: %v", err)
+	}
+
+	if clientCert != nil {
+		t.Errorf("client presented a certificate without one configured")
+	}
+}
+
+func TestTLSReloaderCRLs(t *testing.T) {
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	crlFile := filepath.Join(dir, "crl.pem")
+	rootCA, _ := tlstest.GenerateCert()
+	crl, _ := tlstest.GenerateCRL(5)
+
+	writeFile(t, caFile, rootCA)
+	writeFile(t, crlFile, crl)
+
+	reloader, events := newTestTLSReloader(t, TLSFiles{RootCAFile: caFile, CRLFile: crlFile})
+
+	if _, err := mutualHandshake(t, reloader.GoTLSConfig()); err != nil {
+		t.Fatalf("handshake returned an unexpected error: %v", err)
+	}
+
+	// Revoke the server certificate.
+	crl, _ = tlstest.GenerateCRL(2, 5)
+	writeFile(t, crlFile, crl)
+
+	if event := waitForReload(t, events, crlFile); event.Err != nil {
+		t.Fatalf("reload returned an unexpected error: %v", event.Err)
+	}
+
+	if _, err := mutualHandshake(t, reloader.GoTLSConfig()); !errors.Is(err, ErrCertificateRevoked) {
+		t.Errorf("handshake with a revoked certificate error = %v, want %v", err, ErrCertificateRevoked)
+	}
+}
+
+func TestTLSReloaderVerifiesServerName(t *testing.T) {
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	rootCA, _ := tlstest.GenerateCert()
+
+	writeFile(t, caFile, rootCA)
+
+	reloader, _ := newTestTLSReloader(t, TLSFiles{RootCAFile: caFile})
+	clientConf := reloader.GoTLSConfig().Clone()
+	verifyConnection := clientConf.VerifyConnection
+	clientConf.VerifyConnection = func(cs tls.ConnectionState) error {
+		cs.ServerName = "example.com"
+		return verifyConnection(cs)
+	}
+
+	if _, err := mutualHandshake(t, clientConf); err == nil {
+		t.Errorf("handshake with a mismatched server name should fail")
+	}
+
+	clientConf.VerifyConnection = func(cs tls.ConnectionState) error {
+		cs.ServerName = ""
+		return verifyConnection(cs)
+	}
+
+	if _, err := mutualHandshake(t, clientConf); !errors.Is(err, ErrNoTLSServerName) {
+		t.Errorf("handshake without a server name error = %v, want %v", err, ErrNoTLSServerName)
+	}
+}
+
+func TestNewTLSReloaderErrors(t *testing.T) {
+	if _, err := NewTLSReloader(&TLSConfig{}, TLSReloadOptions{}); !errors.Is(err, ErrNoTLSFiles) {
+		t.Errorf("NewTLSReloader() error = %v, want %v", err, ErrNoTLSFiles)
+	}
+
+	files := TLSFiles{CertFile: filepath.Join(t.TempDir(), "missing.pem")}
+
+	if _, err := NewTLSReloader(&TLSConfig{Files: files}, TLSReloadOptions{}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("NewTLSReloader() error = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestTLSFilesApplyCRLs(t *testing.T) {
+	dir := t.TempDir()
+	crlPath := filepath.Join(dir, "crls")
+	crlFile := filepath.Join(dir, "crl.pem")
+
+	if err := os.Mkdir(crlPath, 0o700); err != nil {
+		t.Fatalf("failed to create %s: %v", crlPath, err)
+	}
+
+	writeFile(t, crlFile, []byte("crl-file"))
+	writeFile(t, filepath.Join(crlPath, "a.pem"), []byte("crl-a"))
+	writeFile(t, filepath.Join(crlPath, ".hidden"), []byte("hidden"))
+
+	files := TLSFiles{CRLFile: crlFile, CRLPath: crlPath}
+
+	contents, err := files.read()
+	if err != nil {
+		t.Fatalf("read() returned an unexpected error: %v", err)
+	}
+
+	tc, err := files.apply(TLSConfig{CRLs: [][]byte{[]byte("old")}}, contents)
+	if err != nil {
+		t.Fatalf("apply() returned an unexpected error: %v", err)
+	}
+
+	expected := [][]byte{[]byte("crl-file"), []byte("crl-a")}
+	if !slices.EqualFunc(tc.CRLs, expected, func(a, b []byte) bool { return string(a) == string(b) }) {
+		t.Errorf("apply() CRLs = %q, want %q", tc.CRLs, expected)
+	}
+
+	if dirs := files.dirs(); !slices.Equal(dirs, []string{dir, crlPath}) {
+		t.Errorf("dirs() = %v, want %v", dirs, []string{dir, crlPath})
+	}
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+var (
+	ErrCertificateRevoked = fmt.Errorf("certificate has been revoked")
+	ErrCRLExpired         = fmt.Errorf("certificate revocation list has expired")
+	ErrInvalidOCSPStaple  = fmt.Errorf("invalid OCSP staple")
+)
+
+// peerCertificateVerifier has the signature of tls.Config.VerifyPeerCertificate.
+type peerCertificateVerifier func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error
+
+// chainPeerCertificateVerifiers returns a tls.Config.VerifyPeerCertificate
+// function that runs each verifier in order and fails on the first error.
+func chainPeerCertificateVerifiers(verifiers []peerCertificateVerifier) peerCertificateVerifier {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		for _, verify := range verifiers {
+			if err := verify(rawCerts, verifiedChains); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// LoadCRLs parses PEM or DER encoded certificate revocation lists. A single
+// PEM input may contain multiple "X509 CRL" blocks.
+func LoadCRLs(crlsBytes [][]byte) ([]*x509.RevocationList, error) {
+	crls := []*x509.RevocationList{}
+
+	for _, crlBytes := range crlsBytes {
+		if len(crlBytes) == 0 {
+			continue
+		}
+
+		if !bytes.Contains(crlBytes, []byte("-----BEGIN")) {
+			crl, err := x509.ParseRevocationList(crlBytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse DER CRL: %w", err)
+			}
+
+			crls = append(crls, crl)
+
+			continue
+		}
+
+		for block, rest := pem.Decode(crlBytes); block != nil; block, rest = pem.Decode(rest) {
+			if block.Type != "X509 CRL" {
+				continue
+			}
+
+			crl, err := x509.ParseRevocationList(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse PEM CRL: %w", err)
+			}
+
+			crls = append(crls, crl)
+		}
+	}
+
+	return crls, nil
+}
+
+// newCRLVerifier returns a verifier that rejects a verified chain when any of
+// its certificates is listed in a CRL signed by that certificate's issuer, or
+// when such a CRL is past its next update. Without verified chains, e.g. when
+// the pins replace CA verification, the chain presented by the server is
+// checked instead.
+func newCRLVerifier(crls []*x509.RevocationList) peerCertificateVerifier {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(verifiedChains) == 0 {
+			chain := []*x509.Certificate{}
+
+			for _, rawCert := range rawCerts {
+				cert, err := x509.ParseCertificate(rawCert)
+				if err != nil {
+					return fmt.Errorf("failed to parse server certificate: %w", err)
+				}
+
+				chain = append(chain, cert)
+			}
+
+			verifiedChains = [][]*x509.Certificate{chain}
+		}
+
+		for _, chain := range verifiedChains {
+			for i, cert := range chain {
+				issuer := cert
+				if i+1 < len(chain) {
+					issuer = chain[i+1]
+				}
+
+				if err := checkCRLs(crls, cert, issuer); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+func checkCRLs(crls []*x509.RevocationList, cert, issuer *x509.Certificate) error {
+	for _, crl := range crls {
+		if !bytes.Equal(crl.RawIssuer, cert.RawIssuer) || crl.CheckSignatureFrom(issuer) != nil {
+			continue
+		}
+
+		if !crl.NextUpdate.IsZero() && time.Now().After(crl.NextUpdate) {
+			return fmt.Errorf(
+				"%w: CRL of issuer %q expired at %s", ErrCRLExpired, crl.Issuer, crl.NextUpdate.Format(time.RFC3339),
+			)
+		}
+
+		for _, entry := range crl.RevokedCertificateEntries {
+			if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return fmt.Errorf(
+					"%w: subject %q serial %s revoked at %s",
+					ErrCertificateRevoked, cert.Subject, cert.SerialNumber, entry.RevocationTime.Format(time.RFC3339),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// verifyOCSPStaple is a tls.Config.VerifyConnection function that requires the
+// server to staple a valid OCSP response reporting its certificate as good.
+func verifyOCSPStaple(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return fmt.Errorf("%w: no peer certificate", ErrInvalidOCSPStaple)
+	}
+
+	if len(cs.OCSPResponse) == 0 {
+		return fmt.Errorf("%w: server did not staple an OCSP response", ErrInvalidOCSPStaple)
+	}
+
+	leaf := cs.PeerCertificates[0]
+	issuer := leaf
+
+	if len(cs.VerifiedChains) > 0 && len(cs.VerifiedChains[0]) > 1 {
+		issuer = cs.VerifiedChains[0][1]
+	} else if len(cs.PeerCertificates) > 1 {
+		issuer = cs.PeerCertificates[1]
+	}
+
+	resp, err := ocsp.ParseResponseForCert(cs.OCSPResponse, leaf, issuer)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOCSPStaple, err)
+	}
+
+	switch resp.Status {
+	case ocsp.Good:
+	case ocsp.Revoked:
+		return fmt.Errorf(
+			"%w: subject %q serial %s revoked at %s according to OCSP",
+			ErrCertificateRevoked, leaf.Subject, leaf.SerialNumber, resp.RevokedAt.Format(time.RFC3339),
+		)
+	default:
+		return fmt.Errorf("%w: certificate status unknown", ErrInvalidOCSPStaple)
+	}
+
+	if !resp.NextUpdate.IsZero() && time.Now().After(resp.NextUpdate) {
+		return fmt.Errorf("%w: response expired at %s", ErrInvalidOCSPStaple, resp.NextUpdate.Format(time.RFC3339))
+	}
+
+	return nil
+}
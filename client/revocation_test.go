@@ -0,0 +1,240 @@
+package client
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aerospike/tools-common-go/internal/tlstest"
+	"golang.org/x/crypto/ocsp"
+)
+
+// handshake performs a TLS handshake between a client using clientConf and a
+// server presenting the given certificate and OCSP staple.
+func handshake(t *testing.T, clientConf *tls.Config, certPEM, staple []byte) error {
+	t.Helper()
+
+	serverCert, err := tls.X509KeyPair(certPEM, tlstest.LeafKeyFileBytes)
+	if err != nil {
+		t.Fatalf("failed to load server certificate: %v", err)
+	}
+
+	serverCert.OCSPStaple = staple
+	clientConn, serverConn := net.Pipe()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		server := tls.Server(serverConn, &tls.Config{ //nolint:gosec // test server
+			Certificates: []tls.Certificate{serverCert},
+		})
+		_ = server.Handshake()
+
+		serverConn.Close()
+	}()
+
+	clientConf = clientConf.Clone()
+	clientConf.ServerName = "localhost"
+	client := tls.Client(clientConn, clientConf)
+	err = client.Handshake()
+
+	clientConn.Close()
+	<-done
+
+	return err
+}
+
+func TestNewGoTLSConfigCRL(t *testing.T) {
+	rootCA, _ := tlstest.GenerateCert()
+	goodCert, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(2))
+	revokedCert, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(3))
+	crl, _ := tlstest.GenerateCRL(3, 4)
+
+	tc := &TLSConfig{
+		RootCA:                 [][]byte{rootCA},
+		CRLs:                   [][]byte{crl},
+		TLSProtocolsMinVersion: VersionTLSDefaultMin,
+		TLSProtocolsMaxVersion: VersionTLSDefaultMax,
+	}
+
+	tlsConfig, err := tc.NewGoTLSConfig()
+	if err != nil {
+		t.Fatalf("NewGoTLSConfig() returned an unexpected error: %v", err)
+	}
+
+	if tlsConfig.VerifyPeerCertificate == nil {
+		t.Fatalf("NewGoTLSConfig() should set VerifyPeerCertificate when CRLs are configured")
+	}
+
+	if err := handshake(t, tlsConfig, goodCert, nil); err != nil {
+		t.Errorf("handshake with a valid certificate returned an unexpected error: %v", err)
+	}
+
+	if err := handshake(t, tlsConfig, revokedCert, nil); !errors.Is(err, ErrCertificateRevoked) {
+		t.Errorf("handshake with a revoked certificate error = %v, want %v", err, ErrCertificateRevoked)
+	}
+}
+
+func TestNewGoTLSConfigExpiredCRL(t *testing.T) {
+	rootCA, _ := tlstest.GenerateCert()
+	goodCert, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(2))
+	crl, _ := tlstest.GenerateCRLWithNextUpdate(time.Now().Add(-time.Hour), 3)
+
+	tc := &TLSConfig{
+		RootCA:                 [][]byte{rootCA},
+		CRLs:                   [][]byte{crl},
+		TLSProtocolsMinVersion: VersionTLSDefaultMin,
+		TLSProtocolsMaxVersion: VersionTLSDefaultMax,
+	}
+
+	tlsConfig, err := tc.NewGoTLSConfig()
+	if err != nil {
+		t.Fatalf("NewGoTLSConfig() returned an unexpected error: %v", err)
+	}
+
+	if err := handshake(t, tlsConfig, goodCert, nil); !errors.Is(err, ErrCRLExpired) {
+		t.Errorf("handshake with an expired CRL error = %v, want %v", err, ErrCRLExpired)
+	}
+}
+
+func TestCRLVerifierUnverifiedChain(t *testing.T) {
+	rootCA, _ := tlstest.GenerateCert()
+	goodCert, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(2))
+	revokedCert, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(3))
+	crlPEM, _ := tlstest.GenerateCRL(3)
+
+	crls, err := LoadCRLs([][]byte{crlPEM})
+	if err != nil {
+		t.Fatalf("LoadCRLs() returned an unexpected error: %v", err)
+	}
+
+	verify := newCRLVerifier(crls)
+	rawCerts := func(certsPEM ...[]byte) [][]byte {
+		raw := [][]byte{}
+
+		for _, certPEM := range certsPEM {
+			block, _ := pem.Decode(certPEM)
+			raw = append(raw, block.Bytes)
+		}
+
+		return raw
+	}
+
+	// Without verified chains, e.g. with InsecureSkipVerify, the presented
+	// chain is checked.
+	if err := verify(rawCerts(goodCert, rootCA), nil); err != nil {
+		t.Errorf("verifier returned an unexpected error for a valid certificate: %v", err)
+	}
+
+	if err := verify(rawCerts(revokedCert, rootCA), nil); !errors.Is(err, ErrCertificateRevoked) {
+		t.Errorf("verifier error = %v, want %v", err, ErrCertificateRevoked)
+	}
+
+	if err := verify([][]byte{[]byte("not a certificate")}, nil); err == nil {
+		t.Errorf("verifier should fail with an unparsable certificate")
+	}
+}
+
+func TestNewGoTLSConfigInvalidCRL(t *testing.T) {
+	tc := &TLSConfig{
+		CRLs: [][]byte{[]byte("not a crl")},
+	}
+
+	if _, err := tc.NewGoTLSConfig(); err == nil {
+		t.Errorf("NewGoTLSConfig() should fail with an invalid CRL")
+	}
+}
+
+func TestLoadCRLs(t *testing.T) {
+	crl1, _ := tlstest.GenerateCRL(1)
+	crl2, _ := tlstest.GenerateCRL(2, 3)
+	block, _ := pem.Decode(crl1)
+
+	testCases := []struct {
+		name     string
+		input    [][]byte
+		expected int
+	}{
+		{
+			name:     "PEM",
+			input:    [][]byte{crl1},
+			expected: 1,
+		},
+		{
+			name:     "ConcatenatedPEM",
+			input:    [][]byte{append(append([]byte{}, crl1...), crl2...)},
+			expected: 2,
+		},
+		{
+			name:     "DER",
+			input:    [][]byte{block.Bytes},
+			expected: 1,
+		},
+		{
+			name:     "Empty",
+			input:    [][]byte{{}},
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			crls, err := LoadCRLs(tc.input)
+			if err != nil {
+				t.Fatalf("LoadCRLs() returned an unexpected error: %v", err)
+			}
+
+			if len(crls) != tc.expected {
+				t.Errorf("LoadCRLs() returned %d CRLs, want %d", len(crls), tc.expected)
+			}
+		})
+	}
+}
+
+func TestNewGoTLSConfigOCSPStaple(t *testing.T) {
+	rootCA, _ := tlstest.GenerateCert()
+	cert, _ := tlstest.GenerateLeafCert(tlstest.NewLeafTemplate(5))
+	good, _ := tlstest.GenerateOCSPResponse(cert, ocsp.Good)
+	revoked, _ := tlstest.GenerateOCSPResponse(cert, ocsp.Revoked)
+	unknown, _ := tlstest.GenerateOCSPResponse(cert, ocsp.Unknown)
+
+	tc := &TLSConfig{
+		RootCA:                 [][]byte{rootCA},
+		RequireOCSPStaple:      true,
+		TLSProtocolsMinVersion: VersionTLSDefaultMin,
+		TLSProtocolsMaxVersion: VersionTLSDefaultMax,
+	}
+
+	tlsConfig, err := tc.NewGoTLSConfig()
+	if err != nil {
+		t.Fatalf("NewGoTLSConfig() returned an unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		staple   []byte
+		expected error
+	}{
+		{"Good", good, nil},
+		{"Revoked", revoked, ErrCertificateRevoked},
+		{"Unknown", unknown, ErrInvalidOCSPStaple},
+		{"Missing", nil, ErrInvalidOCSPStaple},
+		{"Garbage", []byte("garbage"), ErrInvalidOCSPStaple},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := handshake(t, tlsConfig, cert, tc.staple)
+
+			if tc.expected == nil && err != nil {
+				t.Errorf("handshake returned an unexpected error: %v", err)
+			} else if !errors.Is(err, tc.expected) {
+				t.Errorf("handshake error = %v, want %v", err, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultSeedDialTimeout = 5 * time.Second
+
+var (
+	ErrSeedUnresolvable = fmt.Errorf("failed to resolve seed")
+	ErrSeedUnreachable  = fmt.Errorf("failed to reach seed")
+)
+
+// IPPreference orders the addresses a hostname resolves to.
+type IPPreference int
+
+const (
+	// IPPreferenceNone keeps the order returned by the resolver.
+	IPPreferenceNone IPPreference = iota
+	// IPPreferenceIPv4 lists IPv4 addresses before IPv6 addresses.
+	IPPreferenceIPv4
+	// IPPreferenceIPv6 lists IPv6 addresses before IPv4 addresses.
+	IPPreferenceIPv6
+)
+
+// HostResolver looks up the IP addresses of a host. It is implemented by
+// *net.Resolver and can be replaced to resolve against a fake in tests.
+type HostResolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// ContextDialer dials a network address. It is implemented by *net.Dialer.
+type ContextDialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// SeedOptions configures AerospikeConfig.PrepareSeeds.
+type SeedOptions struct {
+	// Resolver is used when Resolve is set. If nil net.DefaultResolver is
+	// used.
+	Resolver HostResolver
+	// Dialer is used when Check is set. If nil a net.Dialer is used.
+	Dialer ContextDialer
+	// IPPreference orders the resolved addresses of each hostname.
+	IPPreference IPPreference
+	// DialTimeout limits each dial. If zero DefaultSeedDialTimeout is used.
+	DialTimeout time.Duration
+	// DefaultPort is used for seeds without a port. If zero DefaultPort is
+	// used.
+	DefaultPort int
+	// Resolve replaces hostnames by all of their A and AAAA records.
+	Resolve bool
+	// Check dials every seed and reports the result.
+	Check bool
+}
+
+// SeedResult reports the outcome of resolving or dialing a single seed.
+type SeedResult struct {
+	// Err wraps ErrSeedUnresolvable or ErrSeedUnreachable on failure.
+	Err     error
+	Seed    *HostTLSPort
+	Latency time.Duration
+}
+
+func (r SeedResult) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s: %s", r.Seed, r.Err)
+	}
+
+	return fmt.Sprintf("%s: ok (%s)", r.Seed, r.Latency)
+}
+
+// Normalize returns the seeds with duplicates removed, keeping the first
+// occurrence. Hostnames are compared case-insensitively without a trailing
+// dot and IP addresses in their canonical form, which is what the returned
+// seeds contain.
+func (slice HostTLSPortSlice) Normalize() HostTLSPortSlice {
+	normalized := HostTLSPortSlice{}
+
+	for _, seed := range slice {
+		seed = &HostTLSPort{
+			Host:    normalizeHost(seed.Host),
+			TLSName: seed.TLSName,
+			Port:    seed.Port,
+		}
+
+		if !slices.ContainsFunc(normalized, func(other *HostTLSPort) bool { return *other == *seed }) {
+			normalized = append(normalized, seed)
+		}
+	}
+
+	return normalized
+}
+
+func normalizeHost(host string) string {
+	host = strings.TrimSpace(host)
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addr.Unmap().String()
+	}
+
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// ResolveSeeds replaces every hostname seed by one seed for each of its A and
+// AAAA records, ordered by pref. The TLS name and port of the seed are kept.
+// Seeds without a TLS name get the hostname as their TLS name so that TLS
+// connections still send it for SNI and verify the certificate against it.
+// Seeds that are already IP addresses are returned as they are. Seeds that
+// fail to resolve are left out and reported in the returned results. If
+// resolver is nil net.DefaultResolver is used.
+func ResolveSeeds(
+	ctx context.Context, resolver HostResolver, seeds HostTLSPortSlice, pref IPPreference,
+) (HostTLSPortSlice, []SeedResult) {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	resolved := HostTLSPortSlice{}
+	failures := []SeedResult{}
+
+	for _, seed := range seeds {
+		if _, err := netip.ParseAddr(seed.Host); err == nil {
+			resolved = append(resolved, seed)
+			continue
+		}
+
+		start := time.Now()
+
+		addrs, err := resolver.LookupIPAddr(ctx, seed.Host)
+		if err == nil && len(addrs) == 0 {
+			err = fmt.Errorf("no addresses found")
+		}
+
+		if err != nil {
+			failures = append(failures, SeedResult{
+				Seed:    seed,
+				Latency: time.Since(start),
+				Err:     fmt.Errorf("%w %s: %w", ErrSeedUnresolvable, seed.Host, err),
+			})
+
+			continue
+		}
+
+		tlsName := seed.TLSName
+		if tlsName == "" {
+			tlsName = seed.Host
+		}
+
+		for _, addr := range sortIPAddrs(addrs, pref) {
+			resolved = append(resolved, &HostTLSPort{
+				Host:    normalizeHost(addr.String()),
+				TLSName: tlsName,
+				Port:    seed.Port,
+			})
+		}
+	}
+
+	return resolved.Normalize(), failures
+}
+
+func sortIPAddrs(addrs []net.IPAddr, pref IPPreference) []net.IPAddr {
+	if pref == IPPreferenceNone {
+		return addrs
+	}
+
+	rank := func(addr net.IPAddr) int {
+		if (addr.IP.To4() != nil) == (pref == IPPreferenceIPv4) {
+			return 0
+		}
+
+		return 1
+	}
+
+	addrs = slices.Clone(addrs)
+	slices.SortStableFunc(addrs, func(a, b net.IPAddr) int { return rank(a) - rank(b) })
+
+	return addrs
+}
+
+// CheckSeeds dials every seed concurrently over TCP and returns one result
+// per seed in the order of seeds. Each dial is limited to timeout, or
+// DefaultSeedDialTimeout if zero, and seeds without a port are dialed on
+// defaultPort. If dialer is nil a net.Dialer is used.
+func CheckSeeds(
+	ctx context.Context, dialer ContextDialer, seeds HostTLSPortSlice, defaultPort int, timeout time.Duration,
+) []SeedResult {
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	if timeout == 0 {
+		timeout = DefaultSeedDialTimeout
+	}
+
+	results := make([]SeedResult, len(seeds))
+
+	var wg sync.WaitGroup
+
+	for i, seed := range seeds {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i] = checkSeed(ctx, dialer, seed, defaultPort, timeout)
+		}()
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func checkSeed(
+	ctx context.Context, dialer ContextDialer, seed *HostTLSPort, defaultPort int, timeout time.Duration,
+) SeedResult {
+	port := seed.Port
+
+	if port == 0 {
+		port = defaultPort
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := SeedResult{Seed: seed}
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(seed.Host, strconv.Itoa(port)))
+	result.Latency = time.Since(start)
+
+	if err != nil {
+		result.Err = fmt.Errorf("%w %s: %w", ErrSeedUnreachable, seed, err)
+		return result
+	}
+
+	conn.Close()
+
+	return result
+}
+
+// PrepareSeeds returns the normalized seeds of the config, including the
+// seeds of its SRV seeds, resolved to their addresses if opts.Resolve is set.
+// If opts.Check is set every prepared seed is dialed and the results include
+// one entry per prepared seed. The results also include an entry for every
+// seed and SRV seed that failed to resolve. The config is not modified.
+func (ac *AerospikeConfig) PrepareSeeds(ctx context.Context, opts SeedOptions) (HostTLSPortSlice, []SeedResult) {
+	seeds := slices.Clone(ac.Seeds)
+	results := []SeedResult{}
+
+	for _, srv := range ac.SRVSeeds {
+		start := time.Now()
+
+		resolved, err := srv.Resolve(ctx, ac.SRVResolver)
+		if err != nil {
+			results = append(results, SeedResult{
+				Seed:    &HostTLSPort{Host: srv.Name, TLSName: srv.TLSName},
+				Latency: time.Since(start),
+				Err:     fmt.Errorf("%w %s: %w", ErrSeedUnresolvable, srv, err),
+			})
+
+			continue
+		}
+
+		seeds = append(seeds, resolved...)
+	}
+
+	seeds = seeds.Normalize()
+
+	if opts.Resolve {
+		var failures []SeedResult
+
+		seeds, failures = ResolveSeeds(ctx, opts.Resolver, seeds, opts.IPPreference)
+		results = append(results, failures...)
+	}
+
+	if opts.Check {
+		defaultPort := opts.DefaultPort
+
+		if defaultPort == 0 {
+			defaultPort = DefaultPort
+		}
+
+		results = append(results, CheckSeeds(ctx, opts.Dialer, seeds, defaultPort, opts.DialTimeout)...)
+	}
+
+	return seeds, results
+}
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeHostResolver map[string][]net.IPAddr
+
+func (r fakeHostResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	return addrs, nil
+}
+
+func TestHostTLSPortSliceNormalize(t *testing.T) {
+	seeds := HostTLSPortSlice{
+		{Host: "Node1.Example.", Port: 3000},
+		{Host: "node1.example", Port: 3000},
+		{Host: "node1.example", TLSName: "tls-name", Port: 3000},
+		{Host: "::FFFF:127.0.0.1", Port: 3000},
+		{Host: "127.0.0.1", Port: 3000},
+		{Host: "2001:DB8:0:0:0:0:0:1"},
+		{Host: "2001:db8::1"},
+	}
+
+	expected := HostTLSPortSlice{
+		{Host: "node1.example", Port: 3000},
+		{Host: "node1.example", TLSName: "tls-name", Port: 3000},
+		{Host: "127.0.0.1", Port: 3000},
+		{Host: "2001:db8::1"},
+	}
+
+	if actual := seeds.Normalize(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Normalize() = %v, want %v", actual.String(), expected.String())
+	}
+
+	if seeds[0].Host != "Node1.Example." {
+		t.Errorf("Normalize() modified the original seeds")
+	}
+}
+
+func TestResolveSeeds(t *testing.T) {
+	resolver := fakeHostResolver{
+		"node1.example": {
+			{IP: net.ParseIP("2001:db8::1")},
+			{IP: net.ParseIP("10.0.0.1")},
+		},
+		"node2.example": {
+			{IP: net.ParseIP("10.0.0.1")},
+		},
+	}
+	seeds := HostTLSPortSlice{
+		{Host: "node1.example", TLSName: "tls-name", Port: 3000},
+		{Host: "missing.example", Port: 3000},
+		{Host: "10.0.0.2", Port: 3000},
+	}
+
+	testCases := []struct {
+		name     string
+		pref     IPPreference
+		expected HostTLSPortSlice
+	}{
+		{
+			name: "None",
+			pref: IPPreferenceNone,
+			expected: HostTLSPortSlice{
+				{Host: "2001:db8::1", TLSName: "tls-name", Port: 3000},
+				{Host: "10.0.0.1", TLSName: "tls-name", Port: 3000},
+				{Host: "10.0.0.2", Port: 3000},
+			},
+		},
+		{
+			name: "IPv4",
+			pref: IPPreferenceIPv4,
+			expected: HostTLSPortSlice{
+				{Host: "10.0.0.1", TLSName: "tls-name", Port: 3000},
+				{Host: "2001:db8::1", TLSName: "tls-name", Port: 3000},
+				{Host: "10.0.0.2", Port: 3000},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, failures := ResolveSeeds(context.Background(), resolver, seeds, tc.pref)
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("ResolveSeeds() = %v, want %v", actual.String(), tc.expected.String())
+			}
+
+			if len(failures) != 1 || failures[0].Seed != seeds[1] || !errors.Is(failures[0].Err, ErrSeedUnresolvable) {
+				t.Errorf("ResolveSeeds() failures = %v, want %s to be unresolvable", failures, seeds[1])
+			}
+		})
+	}
+
+	// Duplicates created by resolution are removed.
+	actual, _ := ResolveSeeds(context.Background(), resolver, HostTLSPortSlice{
+		{Host: "node2.example", TLSName: "tls-name", Port: 3000},
+		{Host: "10.0.0.1", TLSName: "tls-name", Port: 3000},
+	}, IPPreferenceNone)
+
+	expected := HostTLSPortSlice{{Host: "10.0.0.1", TLSName: "tls-name", Port: 3000}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("ResolveSeeds() = %v, want %v", actual.String(), expected.String())
+	}
+
+	// Seeds without a TLS name keep their hostname for SNI.
+	actual, _ = ResolveSeeds(context.Background(), resolver, HostTLSPortSlice{
+		{Host: "node2.example", Port: 3000},
+	}, IPPreferenceNone)
+
+	expected = HostTLSPortSlice{{Host: "10.0.0.1", TLSName: "node2.example", Port: 3000}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("ResolveSeeds() = %v, want %v", actual.String(), expected.String())
+	}
+}
+
+func TestAerospikeConfigPrepareSeeds(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			conn.Close()
+		}
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+
+	closed.Close()
+
+	ac := &AerospikeConfig{
+		Seeds: HostTLSPortSlice{
+			{Host: "localhost", Port: port},
+			{Host: "127.0.0.1", Port: port},
+			{Host: "127.0.0.1", Port: closedPort},
+			{Host: "missing.example"},
+		},
+	}
+	resolver := fakeHostResolver{"localhost": {{IP: net.ParseIP("127.0.0.1")}}}
+
+	seeds, results := ac.PrepareSeeds(context.Background(), SeedOptions{
+		Resolve:     true,
+		Resolver:    resolver,
+		Check:       true,
+		DialTimeout: time.Second,
+	})
+
+	expected := HostTLSPortSlice{
+		{Host: "127.0.0.1", TLSName: "localhost", Port: port},
+		{Host: "127.0.0.1", Port: port},
+		{Host: "127.0.0.1", Port: closedPort},
+	}
+
+	if !reflect.DeepEqual(seeds, expected) {
+		t.Errorf("PrepareSeeds() = %v, want %v", seeds.String(), expected.String())
+	}
+
+	expectedErrs := []error{ErrSeedUnresolvable, nil, nil, ErrSeedUnreachable}
+
+	if len(results) != len(expectedErrs) {
+		t.Fatalf("PrepareSeeds() returned %d results, want %d: %v", len(results), len(expectedErrs), results)
+	}
+
+	for i, result := range results {
+		if (expectedErrs[i] == nil) != (result.Err == nil) || !errors.Is(result.Err, expectedErrs[i]) {
+			t.Errorf("PrepareSeeds() result %d = %v, want %v", i, result, expectedErrs[i])
+		}
+	}
+
+	if ac.Seeds[0].Host != "localhost" {
+		t.Errorf("PrepareSeeds() modified the config seeds")
+	}
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	SRVService = "aerospike"
+	SRVProto   = "tcp"
+	// DefaultSRVLookupTimeout limits the lookup of each SRV seed.
+	DefaultSRVLookupTimeout = 5 * time.Second
+)
+
+var ErrNoSRVRecords = fmt.Errorf("no usable SRV records found")
+
+// SRVResolver looks up DNS SRV records. It is implemented by *net.Resolver and
+// can be replaced to resolve against a different server or a fake in tests.
+type SRVResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// SRVSeed is a DNS SRV name whose records are the seeds of the cluster. It is
+// only resolved when connecting, see AerospikeConfig.NewHostsContext and
+// AerospikeConfig.NewHosts.
+type SRVSeed struct {
+	Name    string
+	TLSName string
+}
+
+// String formats the SRV seed as srv:name[:tls-name].
+func (s SRVSeed) String() string {
+	if s.TLSName == "" {
+		return "srv:" + s.Name
+	}
+
+	return "srv:" + s.Name + ":" + s.TLSName
+}
+
+// Resolve resolves the SRV seed using ResolveSRVHostTLSPorts. The lookup is
+// limited to DefaultSRVLookupTimeout.
+func (s SRVSeed) Resolve(ctx context.Context, resolver SRVResolver) (HostTLSPortSlice, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultSRVLookupTimeout)
+	defer cancel()
+
+	return ResolveSRVHostTLSPorts(ctx, resolver, s.Name, s.TLSName)
+}
+
+// ResolveSRVHostTLSPorts resolves the DNS SRV records of name into seeds. A
+// name starting with an underscore, e.g. "_aerospike._tcp.cluster.example",
+// is looked up as is. Any other name is looked up as
+// "_aerospike._tcp.<name>". Seeds are ordered by ascending priority and then
+// by descending weight, their ports are taken from the records and tlsName is
+// applied to each of them. The order is deterministic rather than the
+// weighted random selection of RFC 2782, the client tries every seed anyway.
+// If resolver is nil net.DefaultResolver is used.
+func ResolveSRVHostTLSPorts(
+	ctx context.Context, resolver SRVResolver, name, tlsName string,
+) (HostTLSPortSlice, error) {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	service, proto := SRVService, SRVProto
+
+	if strings.HasPrefix(name, "_") {
+		service, proto = "", ""
+	}
+
+	_, records, err := resolver.LookupSRV(ctx, service, proto, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve SRV records for %s: %w", name, err)
+	}
+
+	records = slices.Clone(records)
+	slices.SortStableFunc(records, func(a, b *net.SRV) int {
+		return cmp.Or(cmp.Compare(a.Priority, b.Priority), cmp.Compare(b.Weight, a.Weight))
+	})
+
+	seeds := HostTLSPortSlice{}
+
+	for _, record := range records {
+		target := strings.TrimSuffix(record.Target, ".")
+
+		// A target of "." means the service is not available at this name.
+		if target == "" {
+			continue
+		}
+
+		seeds = append(seeds, &HostTLSPort{
+			Host:    target,
+			TLSName: tlsName,
+			Port:    int(record.Port),
+		})
+	}
+
+	if len(seeds) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoSRVRecords, name)
+	}
+
+	return seeds, nil
+}
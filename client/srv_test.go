@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	as "github.com/aerospike/aerospike-client-go/v8"
+)
+
+type fakeSRVResolver struct {
+	records map[string][]*net.SRV
+}
+
+func (r *fakeSRVResolver) LookupSRV(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	if service != "" || proto != "" {
+		name = "_" + service + "._" + proto + "." + name
+	}
+
+	records, ok := r.records[name]
+	if !ok {
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+
+	return name, records, nil
+}
+
+func TestResolveSRVHostTLSPorts(t *testing.T) {
+	resolver := &fakeSRVResolver{
+		records: map[string][]*net.SRV{
+			"_aerospike._tcp.cluster.example": {
+				{Target: "node3.cluster.example.", Port: 3002, Priority: 20, Weight: 100},
+				{Target: "node1.cluster.example.", Port: 3000, Priority: 10, Weight: 10},
+				{Target: "node2.cluster.example.", Port: 3001, Priority: 10, Weight: 50},
+			},
+			"_aerospike-tls._tcp.cluster.example": {
+				{Target: "node1.cluster.example.", Port: 4333, Priority: 10, Weight: 10},
+			},
+			"_aerospike._tcp.unavailable.example": {
+				{Target: ".", Port: 0, Priority: 0, Weight: 0},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		srvName  string
+		tlsName  string
+		expected HostTLSPortSlice
+		err      error
+	}{
+		{
+			name:    "ServiceName",
+			srvName: "cluster.example",
+			expected: HostTLSPortSlice{
+				{Host: "node2.cluster.example", Port: 3001},
+				{Host: "node1.cluster.example", Port: 3000},
+				{Host: "node3.cluster.example", Port: 3002},
+			},
+		},
+		{
+			name:    "RecordName",
+			srvName: "_aerospike-tls._tcp.cluster.example",
+			tlsName: "tls-name",
+			expected: HostTLSPortSlice{
+				{Host: "node1.cluster.example", TLSName: "tls-name", Port: 4333},
+			},
+		},
+		{
+			name:    "Unavailable",
+			srvName: "unavailable.example",
+			err:     ErrNoSRVRecords,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := ResolveSRVHostTLSPorts(context.Background(), resolver, tc.srvName, tc.tlsName)
+
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Errorf("ResolveSRVHostTLSPorts() error = %v, want %v", err, tc.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ResolveSRVHostTLSPorts() returned an unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("ResolveSRVHostTLSPorts() = %v, want %v", actual.String(), tc.expected.String())
+			}
+		})
+	}
+
+	var dnsErr *net.DNSError
+
+	_, err := ResolveSRVHostTLSPorts(context.Background(), resolver, "missing.example", "")
+	if !errors.As(err, &dnsErr) {
+		t.Errorf("ResolveSRVHostTLSPorts() error = %v, want %T", err, dnsErr)
+	}
+}
+
+func TestAerospikeConfigNewHostsContext(t *testing.T) {
+	resolver := &fakeSRVResolver{
+		records: map[string][]*net.SRV{
+			"_aerospike._tcp.cluster.example": {
+				{Target: "node1.cluster.example.", Port: 3000, Priority: 10, Weight: 10},
+			},
+		},
+	}
+	ac := &AerospikeConfig{
+		Seeds:       HostTLSPortSlice{{Host: "127.0.0.1", Port: 3002}},
+		SRVSeeds:    []SRVSeed{{Name: "cluster.example", TLSName: "tls-name"}},
+		SRVResolver: resolver,
+	}
+
+	hosts, err := ac.NewHostsContext(context.Background())
+	if err != nil {
+		t.Fatalf("NewHostsContext() returned an unexpected error: %v", err)
+	}
+
+	expected := []*as.Host{
+		as.NewHost("127.0.0.1", 3002),
+		{Name: "node1.cluster.example", TLSName: "tls-name", Port: 3000},
+	}
+
+	if !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("NewHostsContext() = %v, want %v", hosts, expected)
+	}
+
+	if hosts := ac.NewHosts(); !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("NewHosts() = %v, want %v", hosts, expected)
+	}
+
+	ac.SRVSeeds = append(ac.SRVSeeds, SRVSeed{Name: "missing.example"})
+
+	var dnsErr *net.DNSError
+
+	if _, err := ac.NewHostsContext(context.Background()); !errors.As(err, &dnsErr) {
+		t.Errorf("NewHostsContext() error = %v, want %T", err, dnsErr)
+	}
+
+	// NewHosts leaves out the SRV seed that fails to resolve.
+	if hosts := ac.NewHosts(); !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("NewHosts() = %v, want %v", hosts, expected)
+	}
+
+	seeds, results := ac.PrepareSeeds(context.Background(), SeedOptions{})
+
+	if len(seeds) != 2 {
+		t.Errorf("PrepareSeeds() = %v, want the seeds of the resolved SRV seed", seeds.String())
+	}
+
+	if len(results) != 1 || !errors.Is(results[0].Err, ErrSeedUnresolvable) {
+		t.Errorf("PrepareSeeds() results = %v, want %s to be unresolvable", results, ac.SRVSeeds[1])
+	}
+}
+
+func TestSRVSeedString(t *testing.T) {
+	if actual := (SRVSeed{Name: "cluster.example"}).String(); actual != "srv:cluster.example" {
+		t.Errorf("String() = %q, want %q", actual, "srv:cluster.example")
+	}
+
+	if actual := (SRVSeed{Name: "cluster.example", TLSName: "tls"}).String(); actual != "srv:cluster.example:tls" {
+		t.Errorf("String() = %q, want %q", actual, "srv:cluster.example:tls")
+	}
+}
@@ -0,0 +1,230 @@
+package client
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"time"
+)
+
+// RedactedSecret replaces passwords and keys in the String, MarshalJSON and
+// MarshalYAML output of the configs.
+const RedactedSecret = "xxxxx"
+
+// CertSummary identifies a certificate without including it.
+type CertSummary struct {
+	Subject           string    `json:"subject" yaml:"subject"`
+	Issuer            string    `json:"issuer" yaml:"issuer"`
+	NotAfter          time.Time `json:"not-after" yaml:"not-after"`
+	SHA256Fingerprint string    `json:"sha256-fingerprint" yaml:"sha256-fingerprint"`
+}
+
+// NewCertSummary summarizes cert.
+func NewCertSummary(cert *x509.Certificate) CertSummary {
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	return CertSummary{
+		Subject:           cert.Subject.String(),
+		Issuer:            cert.Issuer.String(),
+		NotAfter:          cert.NotAfter.UTC(),
+		SHA256Fingerprint: hex.EncodeToString(fingerprint[:]),
+	}
+}
+
+// SummarizeCerts summarizes the certificates in PEM or DER encoded data.
+// Anything else in data, such as private keys, is skipped. It returns nil if
+// data contains no certificates.
+func SummarizeCerts(data []byte) []CertSummary {
+	var summaries []CertSummary
+
+	if certs, err := x509.ParseCertificates(data); err == nil {
+		for _, cert := range certs {
+			summaries = append(summaries, NewCertSummary(cert))
+		}
+
+		return summaries
+	}
+
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+
+		summaries = append(summaries, NewCertSummary(cert))
+	}
+
+	return summaries
+}
+
+// redact returns RedactedSecret if secret is set.
+func redact[T ~string | ~[]byte](secret T) string {
+	if len(secret) == 0 {
+		return ""
+	}
+
+	return RedactedSecret
+}
+
+// tlsConfigSummary is the redacted form of a TLSConfig.
+type tlsConfigSummary struct {
+	Files             *TLSFiles     `json:"files,omitempty" yaml:"files,omitempty"`
+	Key               string        `json:"key,omitempty" yaml:"key,omitempty"`
+	KeyPass           string        `json:"key-pass,omitempty" yaml:"key-pass,omitempty"`
+	PKCS12            string        `json:"pkcs12,omitempty" yaml:"pkcs12,omitempty"`
+	MinProtocol       string        `json:"min-protocol,omitempty" yaml:"min-protocol,omitempty"`
+	MaxProtocol       string        `json:"max-protocol,omitempty" yaml:"max-protocol,omitempty"`
+	RootCA            []CertSummary `json:"root-ca,omitempty" yaml:"root-ca,omitempty"`
+	Cert              []CertSummary `json:"cert,omitempty" yaml:"cert,omitempty"`
+	CipherSuites      []string      `json:"cipher-suites,omitempty" yaml:"cipher-suites,omitempty"`
+	Pins              []string      `json:"pins,omitempty" yaml:"pins,omitempty"`
+	CRLs              int           `json:"crls,omitempty" yaml:"crls,omitempty"`
+	RequireOCSPStaple bool          `json:"require-ocsp-staple" yaml:"require-ocsp-staple"`
+	ExcludeSystemCAs  bool          `json:"exclude-system-cas" yaml:"exclude-system-cas"`
+	PinOnly           bool          `json:"pin-only" yaml:"pin-only"`
+}
+
+func (tc *TLSConfig) summary() *tlsConfigSummary {
+	summary := &tlsConfigSummary{
+		Key:               redact(tc.Key),
+		KeyPass:           redact(tc.KeyPass),
+		PKCS12:            redact(tc.PKCS12),
+		MinProtocol:       tc.TLSProtocolsMinVersion.String(),
+		MaxProtocol:       tc.TLSProtocolsMaxVersion.String(),
+		CRLs:              len(tc.CRLs),
+		RequireOCSPStaple: tc.RequireOCSPStaple,
+		ExcludeSystemCAs:  tc.ExcludeSystemCAs,
+		PinOnly:           tc.PinOnly,
+		Cert:              SummarizeCerts(tc.Cert),
+	}
+
+	for _, rootCA := range tc.RootCA {
+		summary.RootCA = append(summary.RootCA, SummarizeCerts(rootCA)...)
+	}
+
+	for _, id := range tc.TLSCipherSuites {
+		summary.CipherSuites = append(summary.CipherSuites, tls.CipherSuiteName(id))
+	}
+
+	for _, pin := range tc.Pins {
+		summary.Pins = append(summary.Pins, pin.String())
+	}
+
+	if tc.Files != (TLSFiles{}) {
+		files := tc.Files
+		summary.Files = &files
+	}
+
+	return summary
+}
+
+// String returns the JSON encoding of the config, see MarshalJSON.
+func (tc TLSConfig) String() string {
+	data, err := tc.MarshalJSON()
+	if err != nil {
+		return err.Error()
+	}
+
+	return string(data)
+}
+
+// MarshalJSON encodes the config with the key and its password redacted and
+// the certificates summarized. See CertSummary. Like the other methods that
+// redact it has a value receiver so that it also applies to a TLSConfig that
+// is not a pointer, e.g. one embedded in another struct.
+func (tc TLSConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tc.summary())
+}
+
+// MarshalYAML is the YAML equivalent of MarshalJSON.
+func (tc TLSConfig) MarshalYAML() (any, error) {
+	return tc.summary(), nil
+}
+
+// clientPolicySummary is the form of a ClientPolicyConfig with readable
+// durations.
+type clientPolicySummary struct {
+	Timeout               string `json:"timeout" yaml:"timeout"`
+	LoginTimeout          string `json:"login-timeout" yaml:"login-timeout"`
+	IdleTimeout           string `json:"idle-timeout" yaml:"idle-timeout"`
+	TendInterval          string `json:"tend-interval" yaml:"tend-interval"`
+	ClusterName           string `json:"cluster-name,omitempty" yaml:"cluster-name,omitempty"`
+	ConnectionQueueSize   int    `json:"conn-queue-size" yaml:"conn-queue-size"`
+	MinConnectionsPerNode int    `json:"min-conns-per-node" yaml:"min-conns-per-node"`
+	MaxErrorRate          int    `json:"max-error-rate" yaml:"max-error-rate"`
+	ErrorRateWindow       int    `json:"error-rate-window" yaml:"error-rate-window"`
+	FailIfNotConnected    bool   `json:"fail-if-not-connected" yaml:"fail-if-not-connected"`
+}
+
+// aerospikeConfigSummary is the redacted form of an AerospikeConfig.
+type aerospikeConfigSummary struct {
+	ClientPolicy         *clientPolicySummary `json:"client-policy,omitempty" yaml:"client-policy,omitempty"`
+	TLS                  *tlsConfigSummary    `json:"tls,omitempty" yaml:"tls,omitempty"`
+	User                 string               `json:"user,omitempty" yaml:"user,omitempty"`
+	Password             string               `json:"password,omitempty" yaml:"password,omitempty"`
+	AuthMode             string               `json:"auth" yaml:"auth"`
+	Seeds                []string             `json:"seeds" yaml:"seeds"`
+	SRVSeeds             []string             `json:"srv-seeds,omitempty" yaml:"srv-seeds,omitempty"`
+	RackIDs              []int                `json:"rack-ids,omitempty" yaml:"rack-ids,omitempty"`
+	UseServicesAlternate bool                 `json:"services-alternate" yaml:"services-alternate"`
+	RackAware            bool                 `json:"rack-aware" yaml:"rack-aware"`
+}
+
+func (ac *AerospikeConfig) summary() *aerospikeConfigSummary {
+	summary := &aerospikeConfigSummary{
+		Seeds:                []string{},
+		User:                 ac.User,
+		Password:             redact(ac.Password),
+		AuthMode:             authModeNames[ac.AuthMode],
+		UseServicesAlternate: ac.UseServicesAlternate,
+		RackAware:            ac.RackAware,
+		RackIDs:              ac.RackIDs,
+	}
+
+	for _, seed := range ac.Seeds {
+		summary.Seeds = append(summary.Seeds, seed.String())
+	}
+
+	for _, srv := range ac.SRVSeeds {
+		summary.SRVSeeds = append(summary.SRVSeeds, srv.String())
+	}
+
+	if pc := ac.ClientPolicy; pc != nil {
+		summary.ClientPolicy = &clientPolicySummary{
+			Timeout:               pc.Timeout.String(),
+			LoginTimeout:          pc.LoginTimeout.String(),
+			IdleTimeout:           pc.IdleTimeout.String(),
+			TendInterval:          pc.TendInterval.String(),
+			ConnectionQueueSize:   pc.ConnectionQueueSize,
+			MinConnectionsPerNode: pc.MinConnectionsPerNode,
+			MaxErrorRate:          pc.MaxErrorRate,
+			ErrorRateWindow:       pc.ErrorRateWindow,
+			ClusterName:           pc.ClusterName,
+			FailIfNotConnected:    pc.FailIfNotConnected,
+		}
+	}
+
+	if ac.TLS != nil {
+		summary.TLS = ac.TLS.summary()
+	}
+
+	return summary
+}
+
+// MarshalJSON encodes the config with the password redacted. The TLS config
+// is encoded as by TLSConfig.MarshalJSON. SRVSeeds are listed unresolved.
+func (ac AerospikeConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ac.summary())
+}
+
+// MarshalYAML is the YAML equivalent of MarshalJSON.
+func (ac AerospikeConfig) MarshalYAML() (any, error) {
+	return ac.summary(), nil
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aerospike/tools-common-go/internal/tlstest"
+)
+
+func TestSummarizeCerts(t *testing.T) {
+	certPEM, err := tlstest.GenerateCert()
+	if err != nil {
+		t.Fatalf("failed to generate certificate: %v", err)
+	}
+
+	block, _ := pem.Decode(certPEM)
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	fingerprint := sha256.Sum256(cert.Raw)
+	expected := []CertSummary{{
+		Subject:           cert.Subject.String(),
+		Issuer:            cert.Issuer.String(),
+		NotAfter:          cert.NotAfter.UTC(),
+		SHA256Fingerprint: hex.EncodeToString(fingerprint[:]),
+	}}
+
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected []CertSummary
+	}{
+		{"PEM", certPEM, expected},
+		{"PEMWithKey", append(append([]byte{}, tlstest.KeyFileBytes...), certPEM...), expected},
+		{"DER", block.Bytes, expected},
+		{"Key", tlstest.KeyFileBytes, nil},
+		{"Empty", nil, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := SummarizeCerts(tc.data); !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("SummarizeCerts() = %v, want %v", actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestAerospikeConfig_MarshalJSON(t *testing.T) {
+	certPEM, err := tlstest.GenerateCert()
+	if err != nil {
+		t.Fatalf("failed to generate certificate: %v", err)
+	}
+
+	ac := &AerospikeConfig{
+		User:     "admin",
+		Password: "secret-password",
+		Seeds:    HostTLSPortSlice{{Host: "127.0.0.1", TLSName: "tls-name", Port: 4333}},
+		SRVSeeds: []SRVSeed{{Name: "cluster.example", TLSName: "srv-tls-name"}},
+		TLS: &TLSConfig{
+			RootCA:                 [][]byte{certPEM},
+			Cert:                   certPEM,
+			Key:                    tlstest.KeyFileBytes,
+			KeyPass:                []byte("secret-key-pass"),
+			TLSProtocolsMinVersion: VersionTLSDefaultMin,
+			TLSProtocolsMaxVersion: VersionTLSDefaultMax,
+		},
+		ClientPolicy: NewDefaultClientPolicyConfig(),
+	}
+
+	actual, err := json.Marshal(ac)
+	if err != nil {
+		t.Fatalf("MarshalJSON() returned an unexpected error: %v", err)
+	}
+
+	for _, secret := range [][]byte{
+		[]byte(ac.Password), ac.TLS.KeyPass, ac.TLS.Key, certPEM, []byte("BEGIN"),
+	} {
+		if bytes.Contains(actual, secret) {
+			t.Errorf("MarshalJSON() = %s, must not contain %q", actual, secret)
+		}
+	}
+
+	for _, expected := range []string{
+		`"password":"xxxxx"`, `"key":"xxxxx"`, `"key-pass":"xxxxx"`, `"seeds":["127.0.0.1:tls-name:4333"]`,
+		`"srv-seeds":["srv:cluster.example:srv-tls-name"]`, `"timeout":"30s"`, `"min-protocol":"TLSV1.2"`,
+		`"sha256-fingerprint":`,
+	} {
+		if !strings.Contains(string(actual), expected) {
+			t.Errorf("MarshalJSON() = %s, want it to contain %s", actual, expected)
+		}
+	}
+
+	yamlValue, err := ac.MarshalYAML()
+	if err != nil {
+		t.Fatalf("MarshalYAML() returned an unexpected error: %v", err)
+	}
+
+	if fromYAML, _ := json.Marshal(yamlValue); !bytes.Equal(fromYAML, actual) {
+		t.Errorf("MarshalYAML() = %s, want the same value as MarshalJSON() = %s", fromYAML, actual)
+	}
+
+	if tlsString := ac.TLS.String(); strings.Contains(tlsString, "secret-key-pass") ||
+		!strings.Contains(tlsString, `"key-pass":"xxxxx"`) {
+		t.Errorf("TLSConfig.String() = %s, want the key password redacted", tlsString)
+	}
+
+	// The configs are also redacted when they are not pointers, e.g. when
+	// embedded in another struct.
+	embedded, err := json.Marshal(struct {
+		TLS    TLSConfig
+		Config AerospikeConfig
+	}{*ac.TLS, *ac})
+	if err != nil {
+		t.Fatalf("json.Marshal() returned an unexpected error: %v", err)
+	}
+
+	for _, secret := range []string{ac.Password, string(ac.TLS.KeyPass), "BEGIN"} {
+		if strings.Contains(string(embedded), secret) {
+			t.Errorf("json.Marshal() = %s, must not contain %q", embedded, secret)
+		}
+	}
+
+	if acString := fmt.Sprint(*ac); strings.Contains(acString, ac.Password) {
+		t.Errorf("fmt.Sprint() = %s, must not contain %q", acString, ac.Password)
+	}
+}
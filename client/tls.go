@@ -5,10 +5,18 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+
+	"software.sslmate.com/src/go-pkcs12"
 )
 
 type TLSProtocol uint16
 
+var (
+	ErrInvalidCACert = fmt.Errorf("invalid CA certificate")
+	ErrInvalidKey    = fmt.Errorf("failed to decode PEM data for key or certificate")
+	ErrKeyDecrypt    = fmt.Errorf("failed to decrypt private key")
+)
+
 const (
 	VersionTLSDefaultMin = tls.VersionTLS12
 	VersionTLSDefaultMax = tls.VersionTLS13
@@ -32,13 +40,40 @@ func (p TLSProtocol) String() string {
 // TLSConfig is a struct that holds the TLS configuration for the client. It is
 // an intermediate type that integrates nicely with our flags.
 type TLSConfig struct {
-	RootCA                 [][]byte
+	// Files are the paths the certificates and keys were read from, if any.
+	// They are only used by TLSReloader.
+	Files TLSFiles
+	// TLSCipherSuites is the list of enabled TLS 1.0-1.2 cipher suites. If
+	// empty the Go defaults are used.
+	TLSCipherSuites []uint16
+	Key             []byte
+	KeyPass         []byte
+	RootCA          [][]byte
+	// PKCS12 is a PKCS#12 (PFX) bundle containing the client certificate,
+	// its key, and optionally CA certificates. It is decrypted using KeyPass
+	// and can not be combined with Cert and Key.
+	PKCS12 []byte
+	// CRLs are PEM or DER encoded certificate revocation lists. A server
+	// certificate chain containing a revoked serial is rejected.
+	CRLs [][]byte
+	// Pins are server certificate or public key hashes. The server must
+	// present a certificate matching one of them in addition to passing CA
+	// verification.
+	Pins                   []TLSPin
 	Cert                   []byte
-	Key                    []byte
-	KeyPass                []byte
 	TLSProtocolsMinVersion TLSProtocol
 	TLSProtocolsMaxVersion TLSProtocol
-	// TLSCipherSuites        []uint16 // TODO
+	// RequireOCSPStaple rejects servers that do not staple a valid OCSP
+	// response for their certificate.
+	RequireOCSPStaple bool
+	// ExcludeSystemCAs builds the CA pool from only RootCA so that public CAs
+	// in the system trust store can not impersonate the cluster.
+	ExcludeSystemCAs bool
+	// PinOnly skips CA verification so that the pins alone decide whether to
+	// trust the server, e.g. a self-signed certificate in an air-gapped
+	// deployment. The pins must then match the server's own certificate.
+	// It requires Pins.
+	PinOnly bool
 }
 
 // NewTLSConfig returns a new TLSConfig that can later be used to create a
@@ -56,7 +91,9 @@ func NewTLSConfig(rootCA [][]byte, cert, key, keyPass []byte, minV, maxV TLSProt
 }
 
 func (tc *TLSConfig) NewGoTLSConfig() (*tls.Config, error) {
-	if len(tc.RootCA) == 0 && len(tc.Cert) == 0 && len(tc.Key) == 0 {
+	if len(tc.RootCA) == 0 && len(tc.Cert) == 0 && len(tc.Key) == 0 && len(tc.PKCS12) == 0 &&
+		len(tc.CRLs) == 0 && !tc.RequireOCSPStaple && len(tc.Pins) == 0 && !tc.ExcludeSystemCAs &&
+		!tc.PinOnly {
 		return nil, nil
 	}
 
@@ -66,12 +103,35 @@ func (tc *TLSConfig) NewGoTLSConfig() (*tls.Config, error) {
 		err        error
 	)
 
-	serverPool = LoadCACerts(tc.RootCA)
+	serverPool, err = LoadCACertsWithOptions(tc.RootCA, CACertOptions{
+		ExcludeSystemCAs: tc.ExcludeSystemCAs,
+		Strict:           true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CA certificates: %w", err)
+	}
 
 	if len(tc.Cert) > 0 || len(tc.Key) > 0 {
 		clientPool, err = LoadServerCertAndKey(tc.Cert, tc.Key, tc.KeyPass)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load client authentication certificate and key `%s`", err)
+			return nil, fmt.Errorf("failed to load client authentication certificate and key `%w`", err)
+		}
+	}
+
+	if len(tc.PKCS12) > 0 {
+		if len(clientPool) > 0 {
+			return nil, fmt.Errorf("a PKCS#12 bundle can not be combined with a certificate and key")
+		}
+
+		cert, caCerts, err := LoadPKCS12(tc.PKCS12, tc.KeyPass)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client authentication PKCS#12 bundle `%w`", err)
+		}
+
+		clientPool = append(clientPool, cert)
+
+		for _, caCert := range caCerts {
+			serverPool.AddCert(caCert)
 		}
 	}
 
@@ -82,6 +142,38 @@ func (tc *TLSConfig) NewGoTLSConfig() (*tls.Config, error) {
 		PreferServerCipherSuites: true,
 		MinVersion:               uint16(tc.TLSProtocolsMinVersion),
 		MaxVersion:               uint16(tc.TLSProtocolsMaxVersion),
+		CipherSuites:             tc.TLSCipherSuites,
+	}
+
+	verifiers := []peerCertificateVerifier{}
+
+	if len(tc.CRLs) > 0 {
+		crls, err := LoadCRLs(tc.CRLs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load certificate revocation lists: %w", err)
+		}
+
+		verifiers = append(verifiers, newCRLVerifier(crls))
+	}
+
+	if tc.PinOnly {
+		if len(tc.Pins) == 0 {
+			return nil, fmt.Errorf("pin only verification requires at least one pin")
+		}
+
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec // the pin verifier authenticates the server
+	}
+
+	if len(tc.Pins) > 0 {
+		verifiers = append(verifiers, newPinVerifier(tc.Pins))
+	}
+
+	if len(verifiers) > 0 {
+		tlsConfig.VerifyPeerCertificate = chainPeerCertificateVerifiers(verifiers)
+	}
+
+	if tc.RequireOCSPStaple {
+		tlsConfig.VerifyConnection = verifyOCSPStaple
 	}
 
 	return tlsConfig, nil
@@ -90,38 +182,111 @@ func (tc *TLSConfig) NewGoTLSConfig() (*tls.Config, error) {
 // LoadCACerts returns CA set of certificates (cert pool)
 // reads CA certificate based on the certConfig and adds it to the pool
 func LoadCACerts(certsBytes [][]byte) *x509.CertPool {
-	certificates, err := x509.SystemCertPool()
-	if certificates == nil || err != nil {
-		certificates = x509.NewCertPool()
+	certificates, _ := LoadCACertsWithOptions(certsBytes, CACertOptions{})
+
+	return certificates
+}
+
+// CACertOptions controls how LoadCACertsWithOptions builds the CA pool.
+type CACertOptions struct {
+	// ExcludeSystemCAs starts from an empty pool instead of the system trust
+	// store.
+	ExcludeSystemCAs bool
+	// Strict requires every "CERTIFICATE" block to parse. Otherwise blocks
+	// that do not parse are skipped, as LoadCACerts does. Inputs without
+	// certificates, such as the CRLs and hash links of a c_rehash directory,
+	// are skipped either way.
+	Strict bool
+}
+
+// LoadCACertsWithOptions is LoadCACerts with control over the system trust
+// store and parsing errors. It only returns an error if opts.Strict is set.
+func LoadCACertsWithOptions(certsBytes [][]byte, opts CACertOptions) (*x509.CertPool, error) {
+	certificates := x509.NewCertPool()
+
+	if !opts.ExcludeSystemCAs {
+		systemCerts, err := x509.SystemCertPool()
+		if systemCerts != nil && err == nil {
+			certificates = systemCerts
+		}
 	}
 
-	for _, cert := range certsBytes {
-		if len(cert) > 0 {
-			certificates.AppendCertsFromPEM(cert)
+	for i, certBytes := range certsBytes {
+		if len(certBytes) == 0 {
+			continue
+		}
+
+		if !opts.Strict {
+			certificates.AppendCertsFromPEM(certBytes)
+			continue
+		}
+
+		blockIdx := 0
+
+		for block, rest := pem.Decode(certBytes); block != nil; block, rest = pem.Decode(rest) {
+			blockIdx++
+
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("%w: PEM block %d of CA input %d: %w", ErrInvalidCACert, blockIdx, i+1, err)
+			}
+
+			certificates.AddCert(cert)
 		}
 	}
 
-	return certificates
+	return certificates, nil
 }
 
 // LoadServerCertAndKey reads server certificate and associated key file based on certConfig and keyConfig
 // returns parsed server certificate
-// if the private key is encrypted, it will be decrypted using key file passphrase
+// if the private key is encrypted, it will be decrypted using key file passphrase. Both PKCS#8
+// "ENCRYPTED PRIVATE KEY" blocks and legacy RFC 1423 encrypted PEM blocks are supported.
 func LoadServerCertAndKey(certFileBytes, keyFileBytes, keyPassBytes []byte) ([]tls.Certificate, error) {
 	var certificates []tls.Certificate
 
+	keyPEM, err := decryptKeyPEM(keyFileBytes, keyPassBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := tls.X509KeyPair(certFileBytes, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add certificate and key to the pool: `%w`", err)
+	}
+
+	certificates = append(certificates, cert)
+
+	return certificates, nil
+}
+
+// decryptKeyPEM returns the first PEM block of keyFileBytes re-encoded
+// without encryption.
+func decryptKeyPEM(keyFileBytes, keyPassBytes []byte) ([]byte, error) {
 	// Decode PEM data
 	keyBlock, _ := pem.Decode(keyFileBytes)
 
 	if keyBlock == nil {
-		return nil, fmt.Errorf("failed to decode PEM data for key or certificate")
+		return nil, ErrInvalidKey
 	}
 
 	// Check and Decrypt the Key Block using passphrase
-	if x509.IsEncryptedPEMBlock(keyBlock) { //nolint:staticcheck // This needs to be addressed by aerospike as multiple projects require this functionality
+	if keyBlock.Type == "ENCRYPTED PRIVATE KEY" {
+		decryptedDERBytes, err := DecryptPKCS8PrivateKey(keyBlock.Bytes, keyPassBytes)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting PKCS#8 private key: %w: %w", ErrKeyDecrypt, err)
+		}
+
+		keyBlock.Type = "PRIVATE KEY"
+		keyBlock.Bytes = decryptedDERBytes
+	} else if x509.IsEncryptedPEMBlock(keyBlock) { //nolint:staticcheck // This needs to be addressed by aerospike as multiple projects require this functionality
 		decryptedDERBytes, err := x509.DecryptPEMBlock(keyBlock, keyPassBytes) //nolint:staticcheck // This needs to be addressed by aerospike as multiple projects require this functionality
 		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt PEM Block: `%s`", err)
+			return nil, fmt.Errorf("decrypting PEM block: %w: %w", ErrKeyDecrypt, err)
 		}
 
 		keyBlock.Bytes = decryptedDERBytes
@@ -135,12 +300,29 @@ func LoadServerCertAndKey(certFileBytes, keyFileBytes, keyPassBytes []byte) ([]t
 		return nil, fmt.Errorf("failed to encode PEM data for key or certificate")
 	}
 
-	cert, err := tls.X509KeyPair(certFileBytes, keyPEM)
+	return keyPEM, nil
+}
+
+// LoadPKCS12 decodes a PKCS#12 (PFX) bundle and returns the client certificate
+// with its chain and private key, along with any CA certificates the bundle
+// contains. The bundle is decrypted using the key file passphrase.
+func LoadPKCS12(pfxBytes, passBytes []byte) (tls.Certificate, []*x509.Certificate, error) {
+	key, cert, caCerts, err := pkcs12.DecodeChain(pfxBytes, string(passBytes))
 	if err != nil {
-		return nil, fmt.Errorf("failed to add certificate and key to the pool: `%s`", err)
+		return tls.Certificate{}, nil, fmt.Errorf("failed to decode PKCS#12 bundle: %w", err)
 	}
 
-	certificates = append(certificates, cert)
+	chain := [][]byte{cert.Raw}
 
-	return certificates, nil
+	for _, caCert := range caCerts {
+		chain = append(chain, caCert.Raw)
+	}
+
+	tlsCert := tls.Certificate{
+		Certificate: chain,
+		PrivateKey:  key,
+		Leaf:        cert,
+	}
+
+	return tlsCert, caCerts, nil
 }
@@ -2,7 +2,12 @@ package client
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"testing"
+
+	"github.com/aerospike/tools-common-go/internal/tlstest"
 )
 
 func TestTLSProtocol_String(t *testing.T) {
@@ -23,3 +28,96 @@ func TestTLSProtocol_String(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadPKCS12(t *testing.T) {
+	pfx, err := tlstest.GeneratePKCS12(tlstest.NewLeafTemplate(2), "fakepassphrase")
+	if err != nil {
+		t.Fatalf("GeneratePKCS12() returned an unexpected error: %v", err)
+	}
+
+	cert, caCerts, err := LoadPKCS12(pfx, []byte("fakepassphrase"))
+	if err != nil {
+		t.Fatalf("LoadPKCS12() returned an unexpected error: %v", err)
+	}
+
+	if cert.Leaf == nil || cert.Leaf.Subject.CommonName != "localhost" {
+		t.Errorf("LoadPKCS12() returned an incorrect leaf certificate %v", cert.Leaf)
+	}
+
+	if len(cert.Certificate) != 2 {
+		t.Errorf("LoadPKCS12() returned a chain of length %d, want 2", len(cert.Certificate))
+	}
+
+	if !tlstest.LeafKey.Equal(cert.PrivateKey) {
+		t.Errorf("LoadPKCS12() returned an incorrect private key")
+	}
+
+	if len(caCerts) != 1 || caCerts[0].Subject.CommonName != "Root CA" {
+		t.Errorf("LoadPKCS12() returned incorrect CA certificates %v", caCerts)
+	}
+
+	if _, _, err := LoadPKCS12(pfx, []byte("wrongpassphrase")); err == nil {
+		t.Errorf("LoadPKCS12() should fail with an incorrect password")
+	}
+}
+
+func TestNewGoTLSConfigPKCS12(t *testing.T) {
+	pfx, _ := tlstest.GeneratePKCS12(tlstest.NewLeafTemplate(2), "fakepassphrase")
+	cert, _ := tlstest.GenerateCert()
+
+	tc := &TLSConfig{
+		PKCS12:                 pfx,
+		KeyPass:                []byte("fakepassphrase"),
+		TLSProtocolsMinVersion: VersionTLSDefaultMin,
+		TLSProtocolsMaxVersion: VersionTLSDefaultMax,
+	}
+
+	tlsConfig, err := tc.NewGoTLSConfig()
+	if err != nil {
+		t.Fatalf("NewGoTLSConfig() returned an unexpected error: %v", err)
+	}
+
+	if len(tlsConfig.Certificates) != 1 {
+		t.Fatalf("NewGoTLSConfig() returned %d certificates, want 1", len(tlsConfig.Certificates))
+	}
+
+	// The bundle's CA must be trusted for verifying the server.
+	if _, err := tlsConfig.Certificates[0].Leaf.Verify(x509.VerifyOptions{Roots: tlsConfig.RootCAs}); err != nil {
+		t.Errorf("NewGoTLSConfig() did not add the bundle CA to RootCAs: %v", err)
+	}
+
+	tc.Cert = cert
+	tc.Key = tlstest.KeyFileBytes
+
+	if _, err := tc.NewGoTLSConfig(); err == nil {
+		t.Errorf("NewGoTLSConfig() should fail when a PKCS#12 bundle is combined with a certificate and key")
+	}
+}
+
+func TestNewGoTLSConfigExcludeSystemCAs(t *testing.T) {
+	rootCA, _ := tlstest.GenerateCert()
+	expectedPool := x509.NewCertPool()
+	expectedPool.AppendCertsFromPEM(rootCA)
+
+	tc := &TLSConfig{
+		RootCA:                 [][]byte{rootCA},
+		ExcludeSystemCAs:       true,
+		TLSProtocolsMinVersion: VersionTLSDefaultMin,
+		TLSProtocolsMaxVersion: VersionTLSDefaultMax,
+	}
+
+	tlsConfig, err := tc.NewGoTLSConfig()
+	if err != nil {
+		t.Fatalf("NewGoTLSConfig() returned an unexpected error: %v", err)
+	}
+
+	if !tlsConfig.RootCAs.Equal(expectedPool) {
+		t.Errorf("NewGoTLSConfig() returned RootCAs containing more than the supplied CA")
+	}
+
+	tc.RootCA = [][]byte{pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("not a certificate")})}
+
+	if _, err := tc.NewGoTLSConfig(); !errors.Is(err, ErrInvalidCACert) {
+		t.Errorf("NewGoTLSConfig() error = %v, want %v", err, ErrInvalidCACert)
+	}
+}
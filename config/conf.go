@@ -1,105 +1,73 @@
 package config
 
 import (
-	"fmt"
-	"strings"
+	"io"
 
 	"github.com/spf13/pflag"
-	"github.com/spf13/viper"
 )
 
-// A map that maps config keys i.e. "cluster.host" to flag names i.e. "host".
-// This is only needed because if "instance". Otherwise we would just run
-// RegisterAlias inside the BindPFlags function.
-var configToFlagMap = map[string]string{}
-var confDirs = []string{".", DefaultConfDir}
-var confName = DefaultConfName
+// defaultLoader is used by the package level functions. It loads the config
+// into the global viper instance.
+var defaultLoader = newLoader(nil)
 
-// SetConfDirs sets the directories to search for the config file when a file
-// name is not explicitly provided. If a file name is explicitly provided viper
-// checks both relative and absolute file paths.
+// SetConfDirs sets the directories to search for the config file, with the
+// highest priority first. See Loader.SetConfDirs.
 func SetDefaultConfDirs(dirs []string) {
-	confDirs = dirs
+	defaultLoader.SetConfDirs(dirs)
+}
+
+// SetMerge enables or disables merging the config files of all search
+// directories in InitConfig. See Loader.SetMerge.
+func SetMerge(merge bool) {
+	defaultLoader.SetMerge(merge)
+}
+
+// ConfigFiles returns the config files loaded by the last InitConfig, in the
+// order they were loaded.
+func ConfigFiles() []string {
+	return defaultLoader.ConfigFiles()
 }
 
 // SetConfName sets the name of the config file to search for. For aerospike
 // tools this is astools but for asvec it is asvec.
 func SetDefaultConfName(name string) {
-	confName = name
+	defaultLoader.SetConfName(name)
+}
+
+// SetEnvPrefix enables reading flags from environment variables with the
+// given prefix in InitConfig and SetFlags. See Loader.SetEnvPrefix.
+func SetEnvPrefix(prefix string) {
+	defaultLoader.SetEnvPrefix(prefix)
+}
+
+// SetValidation sets how InitConfig handles unknown keys and sections in the
+// config file. See Loader.SetValidation.
+func SetValidation(mode ValidationMode) {
+	defaultLoader.SetValidation(mode)
+}
+
+// SetWarningOutput sets where ValidationWarn writes its warnings.
+func SetWarningOutput(w io.Writer) {
+	defaultLoader.SetWarningOutput(w)
+}
+
+// RegisterSection registers a section of the config file that is not bound to
+// flags, such as [asadm], so that validation accepts it. If no keys are given
+// every key in the section is accepted.
+func RegisterSection(section string, keys ...string) {
+	defaultLoader.RegisterSection(section, keys...)
 }
 
 // InitConfig reads in config file and ENV variables if set. Should be called
 // from the root commands PersistentPreRunE function with the flags of the current command.
+// It uses the global viper instance, see Loader for loading a config into its
+// own instance.
 func InitConfig(userProvidedCfgFile, instance string, flags *pflag.FlagSet) (string, error) {
-	if userProvidedCfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(userProvidedCfgFile)
-
-		if strings.HasSuffix(userProvidedCfgFile, ".conf") {
-			// If .conf then explicitly set type to toml.
-			viper.SetConfigType("toml")
-		}
-	} else {
-		for _, d := range confDirs {
-			viper.AddConfigPath(d)
-		}
-
-		viper.SetConfigName(confName)
-	}
-
-	if err := viper.ReadInConfig(); err != nil {
-		if userProvidedCfgFile != "" {
-			// User provided specific file, so we should return an error no
-			// matter what.
-			return "", fmt.Errorf("failed to read config file: %w", err)
-		} else if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			// We are relying on the default config file destination. If the
-			// file is not found don't consider it an error.
-			viper.SetConfigName(confName + ".conf")
-			viper.SetConfigType("toml")
-
-			if err = viper.ReadInConfig(); err != nil {
-				return "", nil
-			}
-		} else {
-			return "", fmt.Errorf("failed to read config file: %w", err)
-		}
-	}
-
-	return viper.ConfigFileUsed(), SetFlags(instance, flags)
+	return defaultLoader.InitConfig(userProvidedCfgFile, instance, flags)
 }
 
 func SetFlags(instance string, flags *pflag.FlagSet) error {
-	var persistedErr error
-
-	flags.VisitAll(func(f *pflag.Flag) {
-		// Convert "host" into "cluster_<instance>.host"
-		alias := getAlias(f.Name, instance)
-
-		// Could be done in BindPFlags if not for "instance". Without this
-		// we would need to do viper.GetString("cluster.host") instead of
-		// viper.GetString("host").
-		viper.RegisterAlias(f.Name, alias)
-
-		// We must bind the flags for GetString to return flags as well as
-		// config file values.
-		err := viper.BindPFlag(alias, f)
-		if err != nil {
-			persistedErr = fmt.Errorf("failed to bind flag %s: %s", f.Name, err)
-			return
-		}
-
-		val := viper.GetString(f.Name)
-
-		// Apply the viper config value to the flag when viper has a value
-		if viper.IsSet(f.Name) && !f.Changed {
-			if err := f.Value.Set(val); err != nil {
-				persistedErr = fmt.Errorf("failed to parse flag %s: %s", f.Name, err)
-			}
-		}
-	})
-
-	return persistedErr
+	return defaultLoader.SetFlags(instance, flags)
 }
 
 // BindPFlags binds the flags to viper. Should be called after the flag set is
@@ -108,44 +76,26 @@ func SetFlags(instance string, flags *pflag.FlagSet) error {
 // bind "cluster.host" to the flag "host". If the section is empty then the flag
 // name is used as the key.
 func BindPFlags(flags *pflag.FlagSet, section string) {
-	if section != "" {
-		section += "."
-	}
-
-	flags.VisitAll(func(f *pflag.Flag) {
-		// We need this to handle the "instance" flag. We will Bind the flags later
-		configToFlagMap[f.Name] = section + f.Name
-	})
+	defaultLoader.BindPFlags(flags, section)
 }
 
-// Reset resets the global configToFlagMap and viper instance.
-// Should be called before or after tests that use InitConfig or BindPFlags.
-// If using testify suites call it in the SetupTest function and or
-// SetupSubTests if using suite.T().Run(...).
-func Reset() {
-	configToFlagMap = map[string]string{}
-
-	viper.Reset()
+// Sources returns the source of the final value of every flag set by
+// InitConfig or SetFlags, keyed by flag name.
+func Sources() map[string]Source {
+	return defaultLoader.Sources()
 }
 
-func getAlias(key, instance string) string {
-	if k, ok := configToFlagMap[key]; ok {
-		key = k
-	}
-
-	keySplit := strings.SplitN(key, ".", 2)
-
-	if len(keySplit) == 1 {
-		if instance != "" {
-			return instance + "." + key
-		}
-
-		return key
-	}
-
-	if instance != "" {
-		keySplit[0] += "_" + instance
-	}
+// WriteConfigTable writes a table of every flag with its value and the source
+// recorded by InitConfig or SetFlags to w. See Loader.WriteConfigTable.
+func WriteConfigTable(w io.Writer, flags *pflag.FlagSet) error {
+	return defaultLoader.WriteConfigTable(w, flags)
+}
 
-	return strings.Join(keySplit, ".")
+// Reset resets the flag bindings of the package level functions and the
+// global viper instance. Should be called before or after tests that use
+// InitConfig or BindPFlags. If using testify suites call it in the SetupTest
+// function and or SetupSubTests if using suite.T().Run(...). Tests that use
+// their own Loader do not need it.
+func Reset() {
+	defaultLoader.Reset()
 }
@@ -26,6 +26,11 @@ bool2=false
 str="localhost:3000 instance"
 int=5000
 bool=true
+
+[cluster]
+rack-aware=true
+rack-id=[1, 2]
+list="a,b"
 `
 
 const yamlConfigTxt = `
@@ -43,6 +48,11 @@ group1_instance:
   str: "localhost:3000 instance"
   int: 5000
   bool: true
+
+cluster:
+  rack-aware: true
+  rack-id: [1, 2]
+  list: "a,b"
   `
 
 type ConfigTestSuite struct {
@@ -75,6 +85,8 @@ func (s *ConfigTestSuite) TearDownSuite() {
 
 func (s *ConfigTestSuite) SetupTest() {
 	Reset()
+	// Keep config files on the machine running the tests out of them.
+	SetDefaultConfDirs([]string{s.T().TempDir()})
 }
 
 func (s *ConfigTestSuite) NewCmds(file, instance string) (rootCmd, cmd1, cmd2 *cobra.Command) {
@@ -329,6 +341,36 @@ func (s *ConfigTestSuite) TestInitConfigWithFlagsDefaults() {
 	s.Equal(s.actualCfgFile, s.file)
 }
 
+func (s *ConfigTestSuite) TestInitConfigWithSliceFlags() {
+	rootCmd, cmd1, _ := s.NewCmds(s.file, "")
+
+	flagSet := &pflag.FlagSet{}
+	flagSet.Bool("rack-aware", false, "bool flag")
+	flagSet.IntSlice("rack-id", nil, "int slice flag")
+	flagSet.StringSlice("list", []string{"default"}, "string slice flag")
+	BindPFlags(flagSet, "cluster")
+
+	cmd1.Flags().AddFlagSet(flagSet)
+
+	// Cmd1
+	rootCmd.SetArgs([]string{"test1"})
+	err := rootCmd.Execute()
+
+	s.NoError(err)
+
+	boolVal, err := cmd1.Flags().GetBool("rack-aware")
+	s.NoError(err)
+	s.Equal(true, boolVal)
+
+	intSlice, err := cmd1.Flags().GetIntSlice("rack-id")
+	s.NoError(err)
+	s.Equal([]int{1, 2}, intSlice)
+
+	strSlice, err := cmd1.Flags().GetStringSlice("list")
+	s.NoError(err)
+	s.Equal([]string{"a", "b"}, strSlice)
+}
+
 func TestRunConfigTestSuite(t *testing.T) {
 	files := []struct {
 		file    string
@@ -3,4 +3,7 @@ package config
 const (
 	DefaultConfDir  = "/etc/aerospike"
 	DefaultConfName = "astools"
+	// XDGConfDirName is the directory under $XDG_CONFIG_HOME that is searched
+	// for the config file.
+	XDGConfDirName = "aerospike"
 )
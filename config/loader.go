@@ -0,0 +1,483 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Loader reads a config file and applies its values to the flags bound to it.
+// Each Loader owns its viper instance, search directories, config name and
+// flag bindings so that different loaders can load different config files
+// concurrently. A single Loader must not be used concurrently.
+type Loader struct {
+	// viper is the instance the config is loaded into. If nil the global
+	// viper instance is used, which is what the package level functions do.
+	viper *viper.Viper
+	// A map that maps config keys i.e. "cluster.host" to flag names i.e.
+	// "host". This is only needed because if "instance". Otherwise we would
+	// just run RegisterAlias inside the BindPFlags function.
+	configToFlagMap map[string]string
+	// sources records where the final value of every flag came from.
+	sources map[string]Source
+	// layers holds the config files loaded by InitConfig in order.
+	layers []configLayer
+	// sections holds the sections registered with RegisterSection and their
+	// keys. A nil slice accepts every key.
+	sections      map[string][]string
+	warningOutput io.Writer
+	confName      string
+	// envPrefix enables reading flags from environment variables when set.
+	envPrefix  string
+	confDirs   []string
+	validation ValidationMode
+	merge      bool
+}
+
+// configLayer is a config file loaded by InitConfig and its own values.
+type configLayer struct {
+	viper *viper.Viper
+	file  string
+}
+
+// NewLoader returns a Loader with its own viper instance that merges the
+// DefaultConfName config files found in DefaultConfDir,
+// $XDG_CONFIG_HOME/aerospike and the current directory.
+func NewLoader() *Loader {
+	return newLoader(viper.New())
+}
+
+func newLoader(v *viper.Viper) *Loader {
+	return &Loader{
+		viper:           v,
+		configToFlagMap: map[string]string{},
+		sources:         map[string]Source{},
+		sections:        map[string][]string{},
+		warningOutput:   os.Stderr,
+		confDirs:        defaultConfDirs(),
+		confName:        DefaultConfName,
+		merge:           true,
+	}
+}
+
+// defaultConfDirs returns the default search directories, with the highest
+// priority first. $XDG_CONFIG_HOME defaults to ~/.config.
+func defaultConfDirs() []string {
+	dirs := []string{"."}
+
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		dirs = append(dirs, filepath.Join(xdg, XDGConfDirName))
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", XDGConfDirName))
+	}
+
+	return append(dirs, DefaultConfDir)
+}
+
+// Viper returns the viper instance the config is loaded into.
+func (l *Loader) Viper() *viper.Viper {
+	if l.viper == nil {
+		return viper.GetViper()
+	}
+
+	return l.viper
+}
+
+// SetConfDirs sets the directories to search for the config file, with the
+// highest priority first. When merging is enabled the config files of every
+// directory are loaded and a file given to InitConfig overrides them. If a
+// file name is explicitly provided viper checks both relative and absolute
+// file paths.
+func (l *Loader) SetConfDirs(dirs []string) {
+	l.confDirs = dirs
+}
+
+// SetMerge enables or disables merging the config files of all search
+// directories. When disabled InitConfig only loads the file it is given, or if
+// none the first config file found. Merging is enabled by default.
+func (l *Loader) SetMerge(merge bool) {
+	l.merge = merge
+}
+
+// SetConfName sets the name of the config file to search for. For aerospike
+// tools this is astools but for asvec it is asvec.
+func (l *Loader) SetConfName(name string) {
+	l.confName = name
+}
+
+// SetEnvPrefix enables reading flags from environment variables named after
+// the prefix and the flag. For example, with the prefix ASTOOLS the flag
+// tls-cafile is read from ASTOOLS_TLS_CAFILE, or from ASTOOLS_<INSTANCE>_TLS_CAFILE
+// when an instance is given. Values are parsed as if given on the command line.
+// A flag given on the command line takes precedence over the environment,
+// which takes precedence over the config file and then the flag default. An
+// empty prefix disables reading from the environment, which is the default.
+func (l *Loader) SetEnvPrefix(prefix string) {
+	l.envPrefix = prefix
+}
+
+// InitConfig reads in config files and ENV variables if set. Should be called
+// from the root commands PersistentPreRunE function with the flags of the current command.
+// When merging is enabled, which is the default, the config file found in each
+// search directory is loaded, starting with the last directory, followed by
+// userProvidedCfgFile. Keys in later files override the same keys in earlier
+// files. Otherwise only userProvidedCfgFile, or if empty the first config file
+// found, is loaded. It returns the last file loaded, see ConfigFiles for all
+// of them.
+func (l *Loader) InitConfig(userProvidedCfgFile, instance string, flags *pflag.FlagSet) (string, error) {
+	if err := l.loadConfigFiles(l.findConfigFiles(userProvidedCfgFile)); err != nil {
+		return "", err
+	}
+
+	file := ""
+
+	if len(l.layers) != 0 {
+		file = l.layers[len(l.layers)-1].file
+	}
+
+	for _, layer := range l.layers {
+		if err := l.validate(layer.file); err != nil {
+			return file, err
+		}
+	}
+
+	return file, l.SetFlags(instance, flags)
+}
+
+// findConfigFiles returns the config files to load in the order they are
+// loaded.
+func (l *Loader) findConfigFiles(userProvidedCfgFile string) []string {
+	if !l.merge {
+		if userProvidedCfgFile != "" {
+			return []string{userProvidedCfgFile}
+		}
+
+		if file := l.findConfigFile(l.confDirs...); file != "" {
+			return []string{file}
+		}
+
+		return nil
+	}
+
+	files := []string{}
+
+	for _, dir := range slices.Backward(l.confDirs) {
+		if file := l.findConfigFile(dir); file != "" {
+			files = append(files, file)
+		}
+	}
+
+	if userProvidedCfgFile != "" {
+		// Don't load the same file twice, e.g. --config-file astools.conf.
+		files = slices.DeleteFunc(files, func(file string) bool { return sameFile(file, userProvidedCfgFile) })
+		files = append(files, userProvidedCfgFile)
+	}
+
+	return files
+}
+
+// findConfigFile returns the first config file found in dirs. Files with an
+// extension supported by viper, e.g. astools.toml, are preferred over a
+// astools.conf TOML file.
+func (l *Loader) findConfigFile(dirs ...string) string {
+	for _, names := range [][]string{supportedConfigNames(l.confName), {l.confName + ".conf"}} {
+		for _, dir := range dirs {
+			for _, name := range names {
+				file := filepath.Join(dir, name)
+
+				if info, err := os.Stat(file); err == nil && !info.IsDir() {
+					return file
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+func supportedConfigNames(confName string) []string {
+	names := make([]string, 0, len(viper.SupportedExts))
+
+	for _, ext := range viper.SupportedExts {
+		names = append(names, confName+"."+ext)
+	}
+
+	return names
+}
+
+// loadConfigFiles reads files into viper in order, replacing the previously
+// loaded config.
+func (l *Loader) loadConfigFiles(files []string) error {
+	v := l.Viper()
+	l.layers = nil
+
+	for i, file := range files {
+		layer, err := readConfigFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		v.SetConfigFile(file)
+		v.SetConfigType(configType(file))
+
+		if i == 0 {
+			err = v.ReadInConfig()
+		} else {
+			err = v.MergeInConfig()
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		l.layers = append(l.layers, configLayer{file: file, viper: layer})
+	}
+
+	return nil
+}
+
+// readConfigFile reads file into a new viper instance.
+func readConfigFile(file string) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	v.SetConfigType(configType(file))
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// configType returns toml for .conf files and the extension for other files.
+func configType(file string) string {
+	if strings.HasSuffix(file, ".conf") {
+		return "toml"
+	}
+
+	return strings.TrimPrefix(filepath.Ext(file), ".")
+}
+
+func sameFile(a, b string) bool {
+	aInfo, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+
+	bInfo, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(aInfo, bInfo)
+}
+
+// ConfigFiles returns the config files loaded by the last InitConfig, in the
+// order they were loaded. Keys in later files override earlier ones.
+func (l *Loader) ConfigFiles() []string {
+	files := make([]string, 0, len(l.layers))
+
+	for _, layer := range l.layers {
+		files = append(files, layer.file)
+	}
+
+	return files
+}
+
+// SetFlags binds the flags to the loaded config and sets every flag that was
+// not changed on the command line to its environment or config value. The
+// source of the final value of every flag is recorded, see Sources.
+func (l *Loader) SetFlags(instance string, flags *pflag.FlagSet) error {
+	var persistedErr error
+
+	v := l.Viper()
+
+	flags.VisitAll(func(f *pflag.Flag) {
+		// Convert "host" into "cluster_<instance>.host"
+		alias := l.getAlias(f.Name, instance)
+
+		// Could be done in BindPFlags if not for "instance". Without this
+		// we would need to do viper.GetString("cluster.host") instead of
+		// viper.GetString("host").
+		v.RegisterAlias(f.Name, alias)
+
+		// We must bind the flags for GetString to return flags as well as
+		// config file values.
+		err := v.BindFlagValue(alias, flagValue{f})
+		if err != nil {
+			persistedErr = fmt.Errorf("failed to bind flag %s: %w", f.Name, err)
+			return
+		}
+
+		// Viper gives the environment precedence over the config file but
+		// not over a changed flag.
+		if l.envPrefix != "" {
+			if err := v.BindEnv(alias, l.envName(f.Name, instance)); err != nil {
+				persistedErr = fmt.Errorf("failed to bind flag %s to the environment: %w", f.Name, err)
+				return
+			}
+		}
+
+		l.recordSource(f, alias, instance)
+
+		// Apply the viper config value to the flag when viper has a value
+		if v.IsSet(f.Name) && !f.Changed {
+			if err := l.setFlag(f); err != nil {
+				persistedErr = fmt.Errorf("failed to parse flag %s: %w", f.Name, err)
+			}
+		}
+	})
+
+	return persistedErr
+}
+
+// setFlag sets the flag to its viper config value. A list in the config file,
+// e.g. rack-id = [1, 2], replaces the values of a slice flag. Any other value
+// is parsed as if it was given on the command line.
+func (l *Loader) setFlag(f *pflag.Flag) error {
+	v := l.Viper()
+
+	if list, ok := v.Get(f.Name).([]any); ok {
+		if sliceValue, ok := f.Value.(pflag.SliceValue); ok {
+			vals := make([]string, 0, len(list))
+
+			for _, val := range list {
+				vals = append(vals, fmt.Sprint(val))
+			}
+
+			return sliceValue.Replace(vals)
+		}
+	}
+
+	return f.Value.Set(v.GetString(f.Name))
+}
+
+// flagValue binds a flag to viper like viper.BindPFlag does but reads its
+// value with valueString, so that viper gets the real value of flags that
+// redact their String output.
+type flagValue struct {
+	flag *pflag.Flag
+}
+
+func (f flagValue) HasChanged() bool {
+	return f.flag.Changed
+}
+
+func (f flagValue) Name() string {
+	return f.flag.Name
+}
+
+func (f flagValue) ValueString() string {
+	return valueString(f.flag)
+}
+
+func (f flagValue) ValueType() string {
+	return f.flag.Value.Type()
+}
+
+// valueString returns the value of the flag as a string. Flags holding
+// passwords, keys or certificates, e.g. flags.PasswordFlag and flags.CertFlag,
+// redact their String output and return their value from a Value method
+// instead. A list of values is formatted as [a, b].
+func valueString(f *pflag.Flag) string {
+	switch value := f.Value.(type) {
+	case interface{ Value() []byte }:
+		return string(value.Value())
+	case interface{ Value() [][]byte }:
+		if len(value.Value()) == 0 {
+			return ""
+		}
+
+		vals := []string{}
+
+		for _, val := range value.Value() {
+			vals = append(vals, string(val))
+		}
+
+		return "[" + strings.Join(vals, ", ") + "]"
+	}
+
+	return f.Value.String()
+}
+
+// BindPFlags binds the flags to the loader. Should be called after the flag
+// set is created. The section is prepended to the flag name to create the
+// viper key. For example, if the config is found under the "cluster" section
+// then we will bind "cluster.host" to the flag "host". If the section is empty
+// then the flag name is used as the key.
+func (l *Loader) BindPFlags(flags *pflag.FlagSet, section string) {
+	if section != "" {
+		section += "."
+	}
+
+	flags.VisitAll(func(f *pflag.Flag) {
+		// We need this to handle the "instance" flag. We will Bind the flags later
+		l.configToFlagMap[f.Name] = section + f.Name
+	})
+}
+
+// Reset clears the flag bindings, the registered sections, the recorded
+// sources and the loaded config files. The search directories, config name,
+// environment prefix and validation settings are kept.
+func (l *Loader) Reset() {
+	l.configToFlagMap = map[string]string{}
+	l.sources = map[string]Source{}
+	l.sections = map[string][]string{}
+	l.layers = nil
+
+	if l.viper == nil {
+		viper.Reset()
+	} else {
+		l.viper = viper.New()
+	}
+}
+
+// envName returns the environment variable the flag is read from, e.g.
+// PREFIX_TLS_CAFILE or PREFIX_INSTANCE_TLS_CAFILE for the flag tls-cafile.
+func (l *Loader) envName(name, instance string) string {
+	parts := []string{l.envPrefix}
+
+	if instance != "" {
+		parts = append(parts, instance)
+	}
+
+	parts = append(parts, name)
+
+	return strings.ToUpper(strings.ReplaceAll(strings.Join(parts, "_"), "-", "_"))
+}
+
+// lookupEnv reports whether the environment variable of the flag is set. Like
+// viper, empty values are ignored.
+func (l *Loader) lookupEnv(name, instance string) bool {
+	val, ok := os.LookupEnv(l.envName(name, instance))
+
+	return ok && val != ""
+}
+
+func (l *Loader) getAlias(key, instance string) string {
+	if k, ok := l.configToFlagMap[key]; ok {
+		key = k
+	}
+
+	keySplit := strings.SplitN(key, ".", 2)
+
+	if len(keySplit) == 1 {
+		if instance != "" {
+			return instance + "." + key
+		}
+
+		return key
+	}
+
+	if instance != "" {
+		keySplit[0] += "_" + instance
+	}
+
+	return strings.Join(keySplit, ".")
+}
@@ -0,0 +1,319 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestLoaderParallel(t *testing.T) {
+	t.Parallel()
+
+	// Group only returns once all of its parallel subtests are done.
+	t.Run("Group", func(t *testing.T) {
+		for i := range 8 {
+			t.Run(fmt.Sprintf("Loader%d", i), func(t *testing.T) {
+				t.Parallel()
+
+				file := filepath.Join(t.TempDir(), "astools.conf")
+				txt := fmt.Sprintf(
+					"[cluster]\nhost = \"host%d\"\nport = %d\n\n[cluster_instance]\nhost = \"instance%d\"\n", i, i, i,
+				)
+
+				if err := os.WriteFile(file, []byte(txt), 0o600); err != nil {
+					t.Fatalf("failed to write config file: %v", err)
+				}
+
+				for _, tc := range []struct {
+					instance string
+					host     string
+				}{
+					{"", fmt.Sprintf("host%d", i)},
+					{"instance", fmt.Sprintf("instance%d", i)},
+				} {
+					loader := NewLoader()
+					loader.SetConfDirs([]string{t.TempDir()})
+
+					flagSet := &pflag.FlagSet{}
+					host := flagSet.String("host", "localhost", "host flag")
+					port := flagSet.Int("port", 3000, "port flag")
+
+					loader.BindPFlags(flagSet, "cluster")
+
+					used, err := loader.InitConfig(file, tc.instance, flagSet)
+					if err != nil {
+						t.Fatalf("InitConfig() returned an unexpected error: %v", err)
+					}
+
+					if used != file {
+						t.Errorf("InitConfig() = %s, want %s", used, file)
+					}
+
+					if *host != tc.host || loader.Viper().GetString("host") != tc.host {
+						t.Errorf("InitConfig() set host %s, want %s", *host, tc.host)
+					}
+
+					// The instance section has no port so the flag keeps its default.
+					if expected := map[string]int{"": i, "instance": 3000}[tc.instance]; *port != expected {
+						t.Errorf("InitConfig() set port %d, want %d", *port, expected)
+					}
+				}
+			})
+		}
+	})
+
+	if viper.IsSet("host") {
+		t.Errorf("Loader modified the global viper instance")
+	}
+}
+
+func TestLoaderSearch(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "tool.conf"), []byte("[cluster]\nhost = \"found\"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	loader := NewLoader()
+	loader.SetConfDirs([]string{dir})
+	loader.SetConfName("tool")
+
+	flagSet := &pflag.FlagSet{}
+	host := flagSet.String("host", "localhost", "host flag")
+
+	loader.BindPFlags(flagSet, "cluster")
+
+	used, err := loader.InitConfig("", "", flagSet)
+	if err != nil {
+		t.Fatalf("InitConfig() returned an unexpected error: %v", err)
+	}
+
+	if used != filepath.Join(dir, "tool.conf") || *host != "found" {
+		t.Errorf("InitConfig() = %s with host %s, want %s with host found", used, *host, filepath.Join(dir, "tool.conf"))
+	}
+
+	loader.Reset()
+
+	if loader.Viper().IsSet("host") {
+		t.Errorf("Reset() did not clear the loaded config")
+	}
+}
+
+func TestLoaderEnv(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "astools.conf")
+	txt := "[cluster]\nhost = \"file\"\nuser = \"file\"\nport = 4000\n\n[cluster_prod]\nhost = \"file-prod\"\n"
+
+	if err := os.WriteFile(file, []byte(txt), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	t.Setenv("ASTOOLS_HOST", "env")
+	t.Setenv("ASTOOLS_USER", "env")
+	t.Setenv("ASTOOLS_TLS_CAFILE", "env")
+	t.Setenv("ASTOOLS_RACK_ID", "1,2")
+	t.Setenv("ASTOOLS_PROD_USER", "env-prod")
+
+	testCases := []struct {
+		name     string
+		file     string
+		instance string
+		args     []string
+		expected map[string]string
+	}{
+		{
+			// CLI flag > env > config file > default.
+			name: "Precedence",
+			file: file,
+			args: []string{"--host", "cli"},
+			expected: map[string]string{
+				"host": "cli", "user": "env", "port": "4000", "tls-cafile": "env", "rack-id": "[1,2]", "tls-name": "default",
+			},
+		},
+		{
+			name:     "Instance",
+			file:     file,
+			instance: "prod",
+			expected: map[string]string{
+				"host": "file-prod", "user": "env-prod", "port": "3000", "tls-cafile": "default", "rack-id": "[]",
+			},
+		},
+		{
+			name: "NoConfigFile",
+			expected: map[string]string{
+				"host": "env", "user": "env", "port": "3000", "tls-cafile": "env",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loader := NewLoader()
+			loader.SetEnvPrefix("astools")
+			loader.SetConfDirs([]string{t.TempDir()})
+
+			flagSet := &pflag.FlagSet{}
+			flagSet.String("host", "default", "host flag")
+			flagSet.String("user", "default", "user flag")
+			flagSet.Int("port", 3000, "port flag")
+			flagSet.String("tls-cafile", "default", "tls-cafile flag")
+			flagSet.String("tls-name", "default", "tls-name flag")
+			flagSet.IntSlice("rack-id", nil, "rack-id flag")
+
+			loader.BindPFlags(flagSet, "cluster")
+
+			if err := flagSet.Parse(tc.args); err != nil {
+				t.Fatalf("Parse() returned an unexpected error: %v", err)
+			}
+
+			if _, err := loader.InitConfig(tc.file, tc.instance, flagSet); err != nil {
+				t.Fatalf("InitConfig() returned an unexpected error: %v", err)
+			}
+
+			for name, expected := range tc.expected {
+				if actual := flagSet.Lookup(name).Value.String(); actual != expected {
+					t.Errorf("InitConfig() set %s to %s, want %s", name, actual, expected)
+				}
+			}
+		})
+	}
+
+	// Without a prefix the environment is ignored.
+	loader := NewLoader()
+	loader.SetConfDirs([]string{t.TempDir()})
+
+	flagSet := &pflag.FlagSet{}
+	host := flagSet.String("host", "default", "host flag")
+
+	loader.BindPFlags(flagSet, "cluster")
+
+	if _, err := loader.InitConfig(file, "", flagSet); err != nil {
+		t.Fatalf("InitConfig() returned an unexpected error: %v", err)
+	}
+
+	if *host != "file" {
+		t.Errorf("InitConfig() set host to %s without an environment prefix, want file", *host)
+	}
+}
+
+func TestLoaderDefaultConfDirs(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+
+	expected := []string{".", "/xdg/aerospike", DefaultConfDir}
+
+	if actual := NewLoader().confDirs; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("NewLoader() searches %v, want %v", actual, expected)
+	}
+}
+
+func TestLoaderLayers(t *testing.T) {
+	root := t.TempDir()
+	etc := filepath.Join(root, "etc")
+	xdg := filepath.Join(root, "xdg")
+	local := filepath.Join(root, "local")
+	explicit := filepath.Join(root, "explicit.conf")
+
+	files := map[string]string{
+		filepath.Join(etc, "astools.conf"):   "[cluster]\nhost = \"etc\"\nport = 1000\nuser = \"etc\"\n",
+		filepath.Join(xdg, "astools.yaml"):   "cluster:\n  port: 2000\n  user: xdg\n",
+		filepath.Join(local, "astools.conf"): "[cluster]\nuser = \"local\"\n",
+		explicit:                             "[cluster]\ntls-name = \"explicit\"\n",
+	}
+
+	for file, txt := range files {
+		if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+			t.Fatalf("failed to create config dir: %v", err)
+		}
+
+		if err := os.WriteFile(file, []byte(txt), 0o600); err != nil {
+			t.Fatalf("failed to write config file: %v", err)
+		}
+	}
+
+	layers := []string{
+		filepath.Join(etc, "astools.conf"), filepath.Join(xdg, "astools.yaml"), filepath.Join(local, "astools.conf"),
+	}
+
+	testCases := []struct {
+		name     string
+		file     string
+		noMerge  bool
+		files    []string
+		expected map[string]string
+	}{
+		{
+			name:     "Merge",
+			file:     explicit,
+			files:    append(slices.Clone(layers), explicit),
+			expected: map[string]string{"host": "etc", "port": "2000", "user": "local", "tls-name": "explicit"},
+		},
+		{
+			name:     "MergeWithoutFile",
+			files:    layers,
+			expected: map[string]string{"host": "etc", "port": "2000", "user": "local", "tls-name": "default"},
+		},
+		{
+			name:     "MergeSameFile",
+			file:     filepath.Join(local, "astools.conf"),
+			files:    layers,
+			expected: map[string]string{"host": "etc", "port": "2000", "user": "local", "tls-name": "default"},
+		},
+		{
+			name:     "NoMerge",
+			file:     explicit,
+			noMerge:  true,
+			files:    []string{explicit},
+			expected: map[string]string{"host": "default", "port": "3000", "user": "default", "tls-name": "explicit"},
+		},
+		{
+			// Supported extensions are searched for in every directory before
+			// astools.conf.
+			name:     "NoMergeWithoutFile",
+			noMerge:  true,
+			files:    []string{filepath.Join(xdg, "astools.yaml")},
+			expected: map[string]string{"host": "default", "port": "2000", "user": "xdg", "tls-name": "default"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loader := NewLoader()
+			loader.SetConfDirs([]string{local, xdg, etc})
+			loader.SetMerge(!tc.noMerge)
+
+			flagSet := &pflag.FlagSet{}
+			flagSet.String("host", "default", "host flag")
+			flagSet.Int("port", 3000, "port flag")
+			flagSet.String("user", "default", "user flag")
+			flagSet.String("tls-name", "default", "tls-name flag")
+
+			loader.BindPFlags(flagSet, "cluster")
+
+			used, err := loader.InitConfig(tc.file, "", flagSet)
+			if err != nil {
+				t.Fatalf("InitConfig() returned an unexpected error: %v", err)
+			}
+
+			if actual := loader.ConfigFiles(); !reflect.DeepEqual(actual, tc.files) || used != tc.files[len(tc.files)-1] {
+				t.Errorf("InitConfig() = %s and loaded %v, want %v", used, actual, tc.files)
+			}
+
+			for name, expected := range tc.expected {
+				if actual := flagSet.Lookup(name).Value.String(); actual != expected {
+					t.Errorf("InitConfig() set %s to %s, want %s", name, actual, expected)
+				}
+			}
+
+			if !tc.noMerge && tc.file == "" {
+				if source := loader.Source("port"); source.File != filepath.Join(xdg, "astools.yaml") {
+					t.Errorf("Source(port) = %v, want the XDG config file", source)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+)
+
+// SecretAnnotation marks a flag whose value must not be shown. See MarkSecret.
+const SecretAnnotation = "aerospike_secret"
+
+// maskedValue replaces the value of secret flags in WriteConfigTable. It is
+// the same as client.RedactedSecret, which is not imported so that config does
+// not depend on the Aerospike client.
+const maskedValue = "xxxxx"
+
+// SourceType is where the final value of a flag came from.
+type SourceType int
+
+const (
+	// SourceDefault is the default value of the flag.
+	SourceDefault SourceType = iota
+	// SourceFlag is a flag given on the command line.
+	SourceFlag
+	// SourceEnv is an environment variable, see Loader.SetEnvPrefix.
+	SourceEnv
+	// SourceFile is a config file.
+	SourceFile
+	// SourceOverride is a value set directly on the viper instance.
+	SourceOverride
+)
+
+func (t SourceType) String() string {
+	switch t {
+	case SourceDefault:
+		return "default"
+	case SourceFlag:
+		return "flag"
+	case SourceEnv:
+		return "env"
+	case SourceFile:
+		return "file"
+	case SourceOverride:
+		return "override"
+	}
+
+	return fmt.Sprintf("SourceType(%d)", int(t))
+}
+
+// Source records where the final value of a flag came from.
+type Source struct {
+	// Env is the environment variable when Type is SourceEnv.
+	Env string
+	// File is the config file and Section the section of the value, e.g.
+	// cluster_instance, when Type is SourceFile. Section is empty for keys
+	// outside of a section.
+	File    string
+	Section string
+	Type    SourceType
+}
+
+func (s Source) String() string {
+	switch s.Type {
+	case SourceEnv:
+		return "env " + s.Env
+	case SourceFile:
+		if s.Section == "" {
+			return "file " + s.File
+		}
+
+		return fmt.Sprintf("file %s [%s]", s.File, s.Section)
+	case SourceDefault, SourceFlag, SourceOverride:
+	}
+
+	return s.Type.String()
+}
+
+// MarkSecret marks the named flags as secret so that WriteConfigTable masks
+// their values.
+func MarkSecret(flags *pflag.FlagSet, names ...string) error {
+	for _, name := range names {
+		if err := flags.SetAnnotation(name, SecretAnnotation, []string{"true"}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Sources returns the source of the final value of every flag set by
+// SetFlags, keyed by flag name.
+func (l *Loader) Sources() map[string]Source {
+	return maps.Clone(l.sources)
+}
+
+// Source returns the source of the final value of the named flag. Flags that
+// were not visited by SetFlags are reported as SourceDefault.
+func (l *Loader) Source(name string) Source {
+	return l.sources[name]
+}
+
+// WriteConfigTable writes a table of every flag with its value and source to
+// w, as shown by a --show-config option. The values of flags marked with
+// MarkSecret or with "password" in their name are masked.
+func (l *Loader) WriteConfigTable(w io.Writer, flags *pflag.FlagSet) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "FLAG\tVALUE\tSOURCE")
+
+	flags.VisitAll(func(f *pflag.Flag) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Name, displayValue(f), l.Source(f.Name))
+	})
+
+	return tw.Flush()
+}
+
+func displayValue(f *pflag.Flag) string {
+	val := valueString(f)
+
+	if val != "" && (len(f.Annotations[SecretAnnotation]) != 0 || strings.Contains(f.Name, "password")) {
+		return maskedValue
+	}
+
+	return val
+}
+
+// recordSource records where the final value of f came from. It is called
+// after the flag is bound to alias.
+func (l *Loader) recordSource(f *pflag.Flag, alias, instance string) {
+	v := l.Viper()
+	source := Source{}
+
+	switch {
+	case f.Changed:
+		source.Type = SourceFlag
+	case l.envPrefix != "" && l.lookupEnv(f.Name, instance):
+		source.Type = SourceEnv
+		source.Env = l.envName(f.Name, instance)
+	case v.InConfig(alias):
+		source.Type = SourceFile
+		source.File = v.ConfigFileUsed()
+
+		// Find the last loaded file with the key.
+		for _, layer := range slices.Backward(l.layers) {
+			if layer.viper.InConfig(alias) {
+				source.File = layer.file
+				break
+			}
+		}
+
+		if section, _, found := strings.Cut(alias, "."); found {
+			source.Section = section
+		}
+	case v.IsSet(f.Name):
+		source.Type = SourceOverride
+	}
+
+	l.sources[f.Name] = source
+}
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aerospike/tools-common-go/client"
+	"github.com/spf13/pflag"
+)
+
+func TestLoaderSources(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "astools.conf")
+	txt := "[cluster]\nhost = \"file\"\nport = 4000\npassword = \"file-password\"\ntoken = \"file-token\"\n\n" +
+		"[cluster_prod]\nport = 5000\n"
+
+	if err := os.WriteFile(file, []byte(txt), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	t.Setenv("ASTOOLS_USER", "env")
+	t.Setenv("ASTOOLS_PROD_USER", "env-prod")
+
+	testCases := []struct {
+		name     string
+		instance string
+		expected map[string]Source
+		table    []string
+	}{
+		{
+			name: "Cluster",
+			expected: map[string]Source{
+				"host":     {Type: SourceFlag},
+				"user":     {Type: SourceEnv, Env: "ASTOOLS_USER"},
+				"port":     {Type: SourceFile, File: file, Section: "cluster"},
+				"password": {Type: SourceFile, File: file, Section: "cluster"},
+				"token":    {Type: SourceFile, File: file, Section: "cluster"},
+				"tls-name": {Type: SourceDefault},
+			},
+			table: []string{
+				"FLAG      VALUE  SOURCE",
+				"host      cli    flag",
+				"user      env    env ASTOOLS_USER",
+				"port      4000   file " + file + " [cluster]",
+				"password  xxxxx  file " + file + " [cluster]",
+				"token     xxxxx  file " + file + " [cluster]",
+				"tls-name         default",
+			},
+		},
+		{
+			name:     "Instance",
+			instance: "prod",
+			expected: map[string]Source{
+				"host":     {Type: SourceFlag},
+				"user":     {Type: SourceEnv, Env: "ASTOOLS_PROD_USER"},
+				"port":     {Type: SourceFile, File: file, Section: "cluster_prod"},
+				"password": {Type: SourceDefault},
+				"token":    {Type: SourceDefault},
+				"tls-name": {Type: SourceDefault},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loader := NewLoader()
+			loader.SetConfDirs([]string{t.TempDir()})
+			loader.SetEnvPrefix("ASTOOLS")
+
+			flagSet := &pflag.FlagSet{}
+			flagSet.String("host", "default", "host flag")
+			flagSet.String("user", "default", "user flag")
+			flagSet.Int("port", 3000, "port flag")
+			flagSet.String("password", "", "password flag")
+			flagSet.String("token", "", "token flag")
+			flagSet.String("tls-name", "", "tls-name flag")
+
+			if err := MarkSecret(flagSet, "token"); err != nil {
+				t.Fatalf("MarkSecret() returned an unexpected error: %v", err)
+			}
+
+			loader.BindPFlags(flagSet, "cluster")
+
+			if err := flagSet.Parse([]string{"--host", "cli"}); err != nil {
+				t.Fatalf("Parse() returned an unexpected error: %v", err)
+			}
+
+			if _, err := loader.InitConfig(file, tc.instance, flagSet); err != nil {
+				t.Fatalf("InitConfig() returned an unexpected error: %v", err)
+			}
+
+			if actual := loader.Sources(); !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("Sources() = %v, want %v", actual, tc.expected)
+			}
+
+			if tc.table == nil {
+				return
+			}
+
+			table := &strings.Builder{}
+
+			if err := loader.WriteConfigTable(table, flagSet); err != nil {
+				t.Fatalf("WriteConfigTable() returned an unexpected error: %v", err)
+			}
+
+			if actual := strings.Split(strings.TrimSpace(table.String()), "\n"); !reflect.DeepEqual(actual, tc.table) {
+				t.Errorf("WriteConfigTable() = %q, want %q", actual, tc.table)
+			}
+
+			if strings.Contains(table.String(), "file-password") || strings.Contains(table.String(), "file-token") {
+				t.Errorf("WriteConfigTable() = %s, want the secrets masked", table)
+			}
+		})
+	}
+
+	if err := MarkSecret(&pflag.FlagSet{}, "unknown"); err == nil {
+		t.Errorf("MarkSecret() of an unknown flag should fail")
+	}
+}
+
+// secretValue redacts its String output like flags.PasswordFlag and
+// flags.CertPathFlag do.
+type secretValue[T []byte | [][]byte] struct {
+	value T
+	parse func(string) T
+}
+
+func (v *secretValue[T]) Set(val string) error {
+	v.value = v.parse(val)
+	return nil
+}
+
+func (v *secretValue[T]) Type() string {
+	return "secret"
+}
+
+func (v *secretValue[T]) String() string {
+	return "redacted"
+}
+
+func (v *secretValue[T]) Value() T {
+	return v.value
+}
+
+func TestLoaderSecretValues(t *testing.T) {
+	loader := NewLoader()
+	loader.SetConfDirs([]string{t.TempDir()})
+
+	flagSet := &pflag.FlagSet{}
+	flagSet.Var(&secretValue[[]byte]{parse: func(val string) []byte { return []byte(val) }}, "password", "")
+	flagSet.Var(&secretValue[[][]byte]{parse: func(val string) [][]byte {
+		return [][]byte{[]byte(val), []byte(val + "2")}
+	}}, "cert-path", "")
+
+	if err := MarkSecret(flagSet, "cert-path"); err != nil {
+		t.Fatalf("MarkSecret() returned an unexpected error: %v", err)
+	}
+
+	loader.BindPFlags(flagSet, "cluster")
+
+	if err := flagSet.Parse([]string{"--password", "cli-password", "--cert-path", "cert"}); err != nil {
+		t.Fatalf("Parse() returned an unexpected error: %v", err)
+	}
+
+	if _, err := loader.InitConfig("", "", flagSet); err != nil {
+		t.Fatalf("InitConfig() returned an unexpected error: %v", err)
+	}
+
+	// Viper reads the flags through Value instead of their redacted String.
+	v := loader.Viper()
+
+	if actual := v.GetString("password"); actual != "cli-password" {
+		t.Errorf("GetString(password) = %q, want %q", actual, "cli-password")
+	}
+
+	if actual := v.GetString("cert-path"); actual != "[cert, cert2]" {
+		t.Errorf("GetString(cert-path) = %q, want %q", actual, "[cert, cert2]")
+	}
+
+	table := &strings.Builder{}
+
+	if err := loader.WriteConfigTable(table, flagSet); err != nil {
+		t.Fatalf("WriteConfigTable() returned an unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"FLAG       VALUE  SOURCE",
+		"password   xxxxx  flag",
+		"cert-path  xxxxx  flag",
+	}
+
+	if actual := strings.Split(strings.TrimSpace(table.String()), "\n"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("WriteConfigTable() = %q, want %q", actual, expected)
+	}
+}
+
+func TestSourceString(t *testing.T) {
+	testCases := []struct {
+		source   Source
+		expected string
+	}{
+		{Source{}, "default"},
+		{Source{Type: SourceFlag}, "flag"},
+		{Source{Type: SourceEnv, Env: "ASTOOLS_HOST"}, "env ASTOOLS_HOST"},
+		{Source{Type: SourceFile, File: "/etc/aerospike/astools.conf", Section: "cluster"},
+			"file /etc/aerospike/astools.conf [cluster]"},
+		{Source{Type: SourceFile, File: "astools.conf"}, "file astools.conf"},
+		{Source{Type: SourceOverride}, "override"},
+	}
+
+	for _, tc := range testCases {
+		if actual := tc.source.String(); actual != tc.expected {
+			t.Errorf("String() = %s, want %s", actual, tc.expected)
+		}
+	}
+}
+
+func TestMaskedValue(t *testing.T) {
+	if maskedValue != client.RedactedSecret {
+		t.Errorf("maskedValue = %q, want client.RedactedSecret %q", maskedValue, client.RedactedSecret)
+	}
+}
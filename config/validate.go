@@ -0,0 +1,281 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidConfig = fmt.Errorf("invalid config file")
+
+// ValidationMode controls how InitConfig handles keys in the config file that
+// match no flag or registered section.
+type ValidationMode int
+
+const (
+	// ValidationOff ignores unknown keys and sections.
+	ValidationOff ValidationMode = iota
+	// ValidationWarn writes a warning for every unknown key and section.
+	ValidationWarn
+	// ValidationError makes InitConfig return an error wrapping
+	// ErrInvalidConfig and every *ErrUnknownConfig of the first loaded config
+	// file with unknown keys or sections, whether it was given to InitConfig
+	// or found in a search directory. The errors name the file.
+	ValidationError
+)
+
+// ErrUnknownConfig reports an unknown key or, if Key is empty, an unknown
+// section of a config file. Suggestion is the closest known key or section,
+// if any is close enough.
+type ErrUnknownConfig struct {
+	File       string
+	Section    string
+	Key        string
+	Suggestion string
+}
+
+func (e *ErrUnknownConfig) Error() string {
+	var msg string
+
+	switch {
+	case e.Key == "":
+		msg = fmt.Sprintf("unknown section [%s] in %s", e.Section, e.File)
+	case e.Section == "":
+		msg = fmt.Sprintf("unknown key %q in %s", e.Key, e.File)
+	default:
+		msg = fmt.Sprintf("unknown key %q in section [%s] of %s", e.Key, e.Section, e.File)
+	}
+
+	if e.Suggestion != "" {
+		msg = fmt.Sprintf("%s, did you mean %s?", msg, e.Suggestion)
+	}
+
+	return msg
+}
+
+// SetValidation sets how InitConfig handles unknown keys and sections in the
+// config file. Keys are known if they are bound to a flag with BindPFlags or
+// registered with RegisterSection. Sections named after a known section
+// followed by _<instance> have the keys of the known section. The default is
+// ValidationOff.
+func (l *Loader) SetValidation(mode ValidationMode) {
+	l.validation = mode
+}
+
+// SetWarningOutput sets where ValidationWarn writes its warnings. The default
+// is os.Stderr.
+func (l *Loader) SetWarningOutput(w io.Writer) {
+	l.warningOutput = w
+}
+
+// RegisterSection registers a section of the config file that is not bound to
+// flags, such as the section of another tool, so that validation accepts it.
+// If no keys are given every key in the section is accepted.
+func (l *Loader) RegisterSection(section string, keys ...string) {
+	section = strings.ToLower(section)
+
+	if len(keys) == 0 {
+		l.sections[section] = nil
+		return
+	}
+
+	if known, ok := l.sections[section]; ok && known == nil {
+		return
+	}
+
+	for _, key := range keys {
+		l.sections[section] = append(l.sections[section], strings.ToLower(key))
+	}
+}
+
+// Validate checks every key of the config file against the flags bound with
+// BindPFlags and the sections registered with RegisterSection. It returns an
+// *ErrUnknownConfig for every unknown key and section.
+func (l *Loader) Validate(file string) ([]error, error) {
+	raw, err := readConfigFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	known, open := l.knownKeys()
+	sectionNames := mapKeys(known)
+	unknownSections := map[string]bool{}
+	problems := []error{}
+	keys := raw.AllKeys()
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		section, name, found := strings.Cut(key, ".")
+		if !found {
+			section, name = "", key
+		}
+
+		base, ok := baseSection(section, known)
+
+		switch {
+		case !ok:
+			if !unknownSections[section] {
+				unknownSections[section] = true
+				problems = append(problems, &ErrUnknownConfig{
+					File:       file,
+					Section:    section,
+					Suggestion: suggest(section, sectionNames, "[", "]"),
+				})
+			}
+		case open[base]:
+		case !known[base][name]:
+			problems = append(problems, &ErrUnknownConfig{
+				File:       file,
+				Section:    section,
+				Key:        name,
+				Suggestion: suggest(name, mapKeys(known[base]), "\"", "\""),
+			})
+		}
+	}
+
+	return problems, nil
+}
+
+// validate validates the config file according to the validation mode.
+func (l *Loader) validate(file string) error {
+	if l.validation == ValidationOff || file == "" {
+		return nil
+	}
+
+	problems, err := l.Validate(file)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if l.validation == ValidationError {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(problems...))
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintf(l.warningOutput, "Warning: %s\n", problem)
+	}
+
+	return nil
+}
+
+// knownKeys returns the known keys of every section, with "" for keys outside
+// of a section, and the sections that accept any key.
+func (l *Loader) knownKeys() (known map[string]map[string]bool, open map[string]bool) {
+	known = map[string]map[string]bool{}
+	open = map[string]bool{}
+
+	add := func(section, key string) {
+		if known[section] == nil {
+			known[section] = map[string]bool{}
+		}
+
+		known[section][key] = true
+	}
+
+	for _, key := range l.configToFlagMap {
+		section, name, found := strings.Cut(key, ".")
+		if !found {
+			section, name = "", key
+		}
+
+		add(section, name)
+	}
+
+	for section, keys := range l.sections {
+		if keys == nil {
+			open[section] = true
+			known[section] = map[string]bool{}
+		}
+
+		for _, key := range keys {
+			add(section, key)
+		}
+	}
+
+	return known, open
+}
+
+// baseSection returns the known section that section is or is an instance of,
+// e.g. cluster for cluster_instance. Keys outside of a section are always in
+// the known section "".
+func baseSection(section string, known map[string]map[string]bool) (string, bool) {
+	if _, ok := known[section]; ok || section == "" {
+		return section, true
+	}
+
+	base := ""
+
+	for _, name := range mapKeys(known) {
+		if name != "" && len(name) > len(base) && strings.HasPrefix(section, name+"_") {
+			base = name
+		}
+	}
+
+	return base, base != ""
+}
+
+// suggest returns the candidate closest to name, quoted with prefix and
+// suffix, or an empty string if none is close enough.
+func suggest(name string, candidates []string, prefix, suffix string) string {
+	best := ""
+	bestDistance := len(name)/3 + 2
+
+	for _, candidate := range candidates {
+		if d := levenshtein(name, candidate); candidate != "" && d < bestDistance {
+			best = candidate
+			bestDistance = d
+		}
+	}
+
+	if best == "" {
+		return ""
+	}
+
+	return prefix + best + suffix
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+const validateConfigTxt = `
+[cluster]
+host = "127.0.0.1"
+tls-cafle = "ca.pem"
+
+[cluster_prod]
+port = 3000
+prot = 3000
+
+[clustr]
+host = "127.0.0.1"
+
+[asadm]
+enable = true
+
+[asinfo]
+timeout = 5
+verbose = true
+`
+
+func newValidateLoader(t *testing.T) (*Loader, *pflag.FlagSet, string) {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "astools.conf")
+
+	if err := os.WriteFile(file, []byte(validateConfigTxt), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	loader := NewLoader()
+	loader.SetConfDirs([]string{t.TempDir()})
+
+	flagSet := &pflag.FlagSet{}
+	flagSet.String("host", "localhost", "host flag")
+	flagSet.Int("port", 3000, "port flag")
+	flagSet.String("tls-cafile", "", "tls-cafile flag")
+
+	loader.BindPFlags(flagSet, "cluster")
+	loader.RegisterSection("asadm")
+	loader.RegisterSection("asinfo", "timeout")
+
+	return loader, flagSet, file
+}
+
+func TestLoaderValidate(t *testing.T) {
+	loader, _, file := newValidateLoader(t)
+
+	actual, err := loader.Validate(file)
+	if err != nil {
+		t.Fatalf("Validate() returned an unexpected error: %v", err)
+	}
+
+	expected := []error{
+		&ErrUnknownConfig{File: file, Section: "asinfo", Key: "verbose"},
+		&ErrUnknownConfig{File: file, Section: "cluster", Key: "tls-cafle", Suggestion: `"tls-cafile"`},
+		&ErrUnknownConfig{File: file, Section: "cluster_prod", Key: "prot", Suggestion: `"port"`},
+		&ErrUnknownConfig{File: file, Section: "clustr", Suggestion: "[cluster]"},
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Validate() = %v, want %v", actual, expected)
+	}
+
+	if msg := expected[1].Error(); msg != `unknown key "tls-cafle" in section [cluster] of `+file+
+		`, did you mean "tls-cafile"?` {
+		t.Errorf("Error() = %s", msg)
+	}
+
+	if msg := expected[3].Error(); msg != "unknown section [clustr] in "+file+", did you mean [cluster]?" {
+		t.Errorf("Error() = %s", msg)
+	}
+}
+
+func TestLoaderValidation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mode     ValidationMode
+		searched bool
+		wantErr  bool
+		warnings int
+	}{
+		{"Off", ValidationOff, false, false, 0},
+		{"Warn", ValidationWarn, false, false, 4},
+		{"Error", ValidationError, false, true, 0},
+		// A file found in the search directories, e.g. ./astools.conf, fails
+		// as well.
+		{"ErrorSearchedFile", ValidationError, true, true, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loader, flagSet, file := newValidateLoader(t)
+			warnings := &strings.Builder{}
+
+			loader.SetValidation(tc.mode)
+			loader.SetWarningOutput(warnings)
+
+			userProvidedCfgFile := file
+
+			if tc.searched {
+				loader.SetConfDirs([]string{filepath.Dir(file)})
+				userProvidedCfgFile = ""
+			}
+
+			_, err := loader.InitConfig(userProvidedCfgFile, "", flagSet)
+
+			var unknown *ErrUnknownConfig
+
+			if tc.wantErr != (errors.Is(err, ErrInvalidConfig) && errors.As(err, &unknown)) {
+				t.Fatalf("InitConfig() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if tc.wantErr && unknown.File != file {
+				t.Errorf("InitConfig() error = %v, want it to name %s", err, file)
+			}
+
+			if actual := strings.Count(warnings.String(), "Warning: "); actual != tc.warnings {
+				t.Errorf("InitConfig() wrote %d warnings, want %d: %s", actual, tc.warnings, warnings)
+			}
+
+			// The flags are only applied from a valid config file.
+			if host, _ := flagSet.GetString("host"); (host == "127.0.0.1") == tc.wantErr {
+				t.Errorf("InitConfig() set host to %s", host)
+			}
+		})
+	}
+}
@@ -258,6 +258,8 @@ func (s *ConfTestSuite) SetupSuite() {
 
 func (s *ConfTestSuite) SetupTest() {
 	config.Reset()
+	// Keep config files on the machine running the tests out of them.
+	config.SetDefaultConfDirs([]string{s.T().TempDir()})
 }
 
 func (s *ConfTestSuite) TearDownSuite() {
@@ -11,6 +11,8 @@ import (
 // --auth flag.
 type AuthModeFlag as.AuthMode
 
+var ErrUnknownAuthMode = fmt.Errorf("unrecognized auth mode")
+
 var authModeMap = map[string]as.AuthMode{
 	"INTERNAL": as.AuthModeInternal,
 	"EXTERNAL": as.AuthModeExternal,
@@ -18,13 +20,12 @@ var authModeMap = map[string]as.AuthMode{
 }
 
 func (mode *AuthModeFlag) Set(val string) error {
-	val = strings.ToUpper(val)
-	if val, ok := authModeMap[val]; ok {
-		*mode = AuthModeFlag(val)
+	if authMode, ok := authModeMap[strings.ToUpper(val)]; ok {
+		*mode = AuthModeFlag(authMode)
 		return nil
 	}
 
-	return fmt.Errorf("unrecognized auth mode")
+	return fmt.Errorf("%w %q", ErrUnknownAuthMode, val)
 }
 
 func (mode *AuthModeFlag) Type() string {
@@ -40,3 +41,13 @@ func (mode *AuthModeFlag) String() string {
 
 	return ""
 }
+
+// MarshalJSON encodes the auth mode by name.
+func (mode AuthModeFlag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(mode)
+}
+
+// MarshalYAML encodes the auth mode by name.
+func (mode AuthModeFlag) MarshalYAML() (any, error) {
+	return mode.String(), nil
+}
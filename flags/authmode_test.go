@@ -55,6 +55,13 @@ func (s *AuthModeTestSuite) TestAuthModeFlag() {
 	}
 }
 
+func (s *AuthModeTestSuite) TestAuthModeFlagInvalid() {
+	actual := AuthModeFlag(as.AuthModeExternal)
+
+	s.ErrorIs(actual.Set("KERBEROS"), ErrUnknownAuthMode)
+	s.Equal(AuthModeFlag(as.AuthModeExternal), actual)
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestRunAuthModeTestSuite(t *testing.T) {
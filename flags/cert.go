@@ -1,7 +1,11 @@
 package flags
 
 import (
+	"path/filepath"
 	"strings"
+
+	"github.com/aerospike/tools-common-go/client"
+	"github.com/spf13/pflag"
 )
 
 // CertFlag defines a Cobra compatible flag for
@@ -37,8 +41,20 @@ func (flag *CertFlag) Type() string {
 	return "env-b64:<cert>,b64:<cert>,<cert-file-name>"
 }
 
+// String returns the certificate redacted so that neither certificates nor
+// private keys show up in help output or logs. Use Value to get the data
+// itself.
 func (flag *CertFlag) String() string {
-	return string(*flag)
+	if len(*flag) == 0 {
+		return ""
+	}
+
+	return client.RedactedSecret
+}
+
+// Value returns the certificate data.
+func (flag *CertFlag) Value() []byte {
+	return *flag
 }
 
 // CertFlag defines a Cobra compatible flag for
@@ -62,6 +78,8 @@ func (slice *CertPathFlag) Type() string {
 	return "<cert-path-name>"
 }
 
+// String returns one redacted entry per file read from the path. Use Value to
+// get the data itself.
 func (slice *CertPathFlag) String() string {
 	if len(*slice) == 0 {
 		return ""
@@ -69,9 +87,235 @@ func (slice *CertPathFlag) String() string {
 
 	strList := []string{}
 
-	for _, certBytes := range *slice {
-		strList = append(strList, string(certBytes))
+	for range *slice {
+		strList = append(strList, client.RedactedSecret)
 	}
 
 	return "[" + strings.Join(strList, ", ") + "]"
 }
+
+// Value returns the data of every file read from the path.
+func (slice *CertPathFlag) Value() [][]byte {
+	return *slice
+}
+
+// RawCertFlag is a CertFlag for binary data such as DER encoded revocation
+// lists and PKCS#12 bundles. Files are read as is, without removing a
+// trailing newline.
+// examples include...
+// --tls-crl-file
+// --tls-pkcs12-file
+type RawCertFlag []byte
+
+func (flag *RawCertFlag) Set(val string) error {
+	var (
+		result []byte
+		err    error
+	)
+
+	if path := certFlagPath(val); path != "" {
+		result, err = readFromFile(path, false)
+	} else {
+		var str string
+
+		str, err = flagFormatParser(val, flagFormatB64|flagFormatEnvB64)
+		result = []byte(str)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	*flag = result
+
+	return nil
+}
+
+func (flag *RawCertFlag) Type() string {
+	return "env-b64:<data>,b64:<data>,<file-name>"
+}
+
+// String returns the data redacted, see CertFlag.String.
+func (flag *RawCertFlag) String() string {
+	return (*CertFlag)(flag).String()
+}
+
+// Value returns the data.
+func (flag *RawCertFlag) Value() []byte {
+	return *flag
+}
+
+// RawCertPathFlag is a CertPathFlag for binary data. Files are read as is,
+// without removing a trailing newline.
+// examples include...
+// --tls-crl-path
+type RawCertPathFlag [][]byte
+
+func (slice *RawCertPathFlag) Set(val string) error {
+	resultBytes, err := readFromPath(val, false)
+	if err != nil {
+		return err
+	}
+
+	*slice = resultBytes
+
+	return nil
+}
+
+func (slice *RawCertPathFlag) Type() string {
+	return "<path-name>"
+}
+
+// String returns one redacted entry per file, see CertPathFlag.String.
+func (slice *RawCertPathFlag) String() string {
+	return (*CertPathFlag)(slice).String()
+}
+
+// Value returns the data of every file read from the path.
+func (slice *RawCertPathFlag) Value() [][]byte {
+	return *slice
+}
+
+// secretValue is a flag whose String output is redacted and whose value is
+// returned by Value instead.
+type secretValue[T any] interface {
+	pflag.Value
+	Value() T
+}
+
+// fileSourceFlag wraps a CertFlag or CertPathFlag and records the path its
+// value was read from so that the file can be watched for changes. The path
+// is cleared when the value did not come from a file.
+type fileSourceFlag[T any] struct {
+	value secretValue[T]
+	path  *string
+}
+
+func newFileSourceFlag[T any](value secretValue[T], path *string) *fileSourceFlag[T] {
+	return &fileSourceFlag[T]{
+		value: value,
+		path:  path,
+	}
+}
+
+func (flag *fileSourceFlag[T]) Set(val string) error {
+	if err := flag.value.Set(val); err != nil {
+		return err
+	}
+
+	path := val
+
+	switch any(flag.value).(type) {
+	case *CertPathFlag, *RawCertPathFlag:
+	default:
+		path = certFlagPath(val)
+	}
+
+	if path != "" {
+		path, _ = filepath.Abs(path)
+	}
+
+	*flag.path = path
+
+	return nil
+}
+
+func (flag *fileSourceFlag[T]) Type() string {
+	return flag.value.Type()
+}
+
+func (flag *fileSourceFlag[T]) String() string {
+	return flag.value.String()
+}
+
+// Value returns the data of the wrapped flag.
+func (flag *fileSourceFlag[T]) Value() T {
+	return flag.value.Value()
+}
+
+// certFlagPath returns the file a CertFlag value is read from or an empty
+// string if it is read from elsewhere.
+func certFlagPath(val string) string {
+	sourceType, name, found := strings.Cut(val, ":")
+	if !found {
+		return val
+	}
+
+	switch sourceType {
+	case "file":
+		return name
+	case "env", "env-b64", "b64":
+		return ""
+	}
+
+	return val
+}
+
+// certSummary summarizes the certificates in data. Data without certificates,
+// such as a private key, is redacted.
+func certSummary(data []byte) []any {
+	summaries := []any{}
+
+	if len(data) == 0 {
+		return summaries
+	}
+
+	certs := client.SummarizeCerts(data)
+	if len(certs) == 0 {
+		return append(summaries, client.RedactedSecret)
+	}
+
+	for _, cert := range certs {
+		summaries = append(summaries, cert)
+	}
+
+	return summaries
+}
+
+// MarshalJSON encodes the certificates as client.CertSummary values. Anything
+// else, such as a private key, is redacted.
+func (flag CertFlag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(flag)
+}
+
+// MarshalYAML is the YAML equivalent of MarshalJSON.
+func (flag CertFlag) MarshalYAML() (any, error) {
+	return certSummary(flag), nil
+}
+
+// MarshalJSON encodes the certificates of every file as client.CertSummary
+// values. Files without certificates are redacted.
+func (slice CertPathFlag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(slice)
+}
+
+// MarshalYAML is the YAML equivalent of MarshalJSON.
+func (slice CertPathFlag) MarshalYAML() (any, error) {
+	summaries := []any{}
+
+	for _, data := range slice {
+		summaries = append(summaries, certSummary(data)...)
+	}
+
+	return summaries, nil
+}
+
+// MarshalJSON is the same as CertFlag.MarshalJSON.
+func (flag RawCertFlag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(flag)
+}
+
+// MarshalYAML is the YAML equivalent of MarshalJSON.
+func (flag RawCertFlag) MarshalYAML() (any, error) {
+	return certSummary(flag), nil
+}
+
+// MarshalJSON is the same as CertPathFlag.MarshalJSON.
+func (slice RawCertPathFlag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(slice)
+}
+
+// MarshalYAML is the YAML equivalent of MarshalJSON.
+func (slice RawCertPathFlag) MarshalYAML() (any, error) {
+	return CertPathFlag(slice).MarshalYAML()
+}
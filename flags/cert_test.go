@@ -3,8 +3,11 @@ package flags
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/spf13/pflag"
 )
 
 const (
@@ -152,3 +155,184 @@ func TestCertPath(t *testing.T) {
 		})
 	}
 }
+
+func TestRawCert(t *testing.T) {
+	dir := t.TempDir()
+	der := []byte{0x30, 0x03, 0x02, 0x01, '\n'}
+	file := filepath.Join(dir, "crl.der")
+
+	if err := os.WriteFile(file, der, 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", file, err)
+	}
+
+	testCases := []struct {
+		name    string
+		input   string
+		output  RawCertFlag
+		wantErr bool
+	}{
+		{
+			name:   "File",
+			input:  file,
+			output: RawCertFlag(der),
+		},
+		{
+			name:   "FilePrefix",
+			input:  "file:" + file,
+			output: RawCertFlag(der),
+		},
+		{
+			name:   "B64",
+			input:  "b64:" + testB64EnvVal,
+			output: RawCertFlag("lylelylecrocodile"),
+		},
+		{
+			name:    "Env",
+			input:   "env:FLAG_TEST_RAW_CERT",
+			output:  RawCertFlag{},
+			wantErr: true,
+		},
+		{
+			name:    "FileDoesNotExist",
+			input:   "file:filedoesnotexist",
+			output:  RawCertFlag{},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := RawCertFlag{}
+			err := actual.Set(tc.input)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Set() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(actual, tc.output) {
+				t.Errorf("Set() = %v, want %v", actual, tc.output)
+			}
+		})
+	}
+}
+
+func TestRawCertPath(t *testing.T) {
+	dir := t.TempDir()
+	der := []byte{0x30, 0x03, 0x02, 0x01, '\n'}
+
+	if err := os.WriteFile(filepath.Join(dir, "crl.der"), der, 0o600); err != nil {
+		t.Fatalf("failed to write CRL: %v", err)
+	}
+
+	actual := RawCertPathFlag{}
+
+	if err := actual.Set(dir); err != nil {
+		t.Fatalf("Set() returned an unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(actual, RawCertPathFlag{der}) {
+		t.Errorf("Set() = %v, want %v", actual, RawCertPathFlag{der})
+	}
+
+	if err := actual.Set("./pathdoesnotexist"); err == nil {
+		t.Errorf("Set() should fail with a path that does not exist")
+	}
+}
+
+func TestFileSourceFlag(t *testing.T) {
+	absFilePath, _ := filepath.Abs(testFileDataPath)
+	absCertPath, _ := filepath.Abs("./testdata/cert_path")
+
+	testCases := []struct {
+		name   string
+		value  pflag.Value
+		input  string
+		output string
+	}{
+		{
+			name:   "File",
+			value:  &CertFlag{},
+			input:  testFileDataPath,
+			output: absFilePath,
+		},
+		{
+			name:   "FilePrefix",
+			value:  &CertFlag{},
+			input:  "file:" + testFileDataPath,
+			output: absFilePath,
+		},
+		{
+			name:   "B64",
+			value:  &CertFlag{},
+			input:  "b64:" + testB64EnvVal,
+			output: "",
+		},
+		{
+			name:   "CertPath",
+			value:  &CertPathFlag{},
+			input:  "./testdata/cert_path",
+			output: absCertPath,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var flag pflag.Value
+
+			path := "previous"
+
+			switch value := tc.value.(type) {
+			case *CertFlag:
+				flag = newFileSourceFlag(value, &path)
+			case *CertPathFlag:
+				flag = newFileSourceFlag(value, &path)
+			}
+
+			if err := flag.Set(tc.input); err != nil {
+				t.Fatalf("Set() returned an unexpected error: %v", err)
+			}
+
+			if path != tc.output {
+				t.Errorf("Set() recorded path %q, want %q", path, tc.output)
+			}
+		})
+	}
+}
+
+func TestCertString(t *testing.T) {
+	cert := CertFlag{}
+	certPath := CertPathFlag{}
+
+	if cert.String() != "" || certPath.String() != "" {
+		t.Errorf("String() = %q and %q, want empty", cert.String(), certPath.String())
+	}
+
+	cert = CertFlag("fakecert")
+	certPath = CertPathFlag{[]byte("fakecert"), []byte("fakekey")}
+
+	if actual := cert.String(); actual != "xxxxx" {
+		t.Errorf("CertFlag.String() = %q, want it redacted", actual)
+	}
+
+	if actual := certPath.String(); actual != "[xxxxx, xxxxx]" {
+		t.Errorf("CertPathFlag.String() = %q, want it redacted", actual)
+	}
+
+	if !reflect.DeepEqual(cert.Value(), []byte("fakecert")) {
+		t.Errorf("CertFlag.Value() = %q, want %q", cert.Value(), "fakecert")
+	}
+
+	if !reflect.DeepEqual(certPath.Value(), [][]byte{[]byte("fakecert"), []byte("fakekey")}) {
+		t.Errorf("CertPathFlag.Value() = %q, want the file data", certPath.Value())
+	}
+
+	// The flags recording their file are redacted the same way.
+	path := ""
+	sourceFlag := newFileSourceFlag(&certPath, &path)
+
+	if sourceFlag.String() != "[xxxxx, xxxxx]" || !reflect.DeepEqual(sourceFlag.Value(), certPath.Value()) {
+		t.Errorf("fileSourceFlag String() = %q and Value() = %q, want the wrapped flag's", sourceFlag.String(),
+			sourceFlag.Value())
+	}
+}
@@ -22,8 +22,8 @@ func NewConfFileFlags() *ConfFileFlags {
 func (cf *ConfFileFlags) NewFlagSet(fmtUsage UsageFormatter) *pflag.FlagSet {
 	f := &pflag.FlagSet{}
 
-	f.StringVar(&cf.File, "config-file", "", fmtUsage(fmt.Sprintf("Config file (default is %s/%s)", config.DefaultConfDir, config.DefaultConfName)))                                                                                    //nolint:lll //Reason: Wrapping this line would make editing difficult.
-	f.StringVar(&cf.Instance, "instance", "", fmtUsage("For support of the aerospike tools toml schema. Sections with the instance are read. e.g in the case where instance 'a' is specified sections 'cluster_a', 'uda_a' are read.")) //nolint:lll //Reason: Wrapping this line would make editing difficult.
+	f.StringVar(&cf.File, "config-file", "", fmtUsage(fmt.Sprintf("Config file. Its keys override the %[2]s config files in %[1]s, $XDG_CONFIG_HOME/%[3]s and the current directory, which are loaded in that order.", config.DefaultConfDir, config.DefaultConfName, config.XDGConfDirName))) //nolint:lll //Reason: Wrapping this line would make editing difficult.
+	f.StringVar(&cf.Instance, "instance", "", fmtUsage("For support of the aerospike tools toml schema. Sections with the instance are read. e.g in the case where instance 'a' is specified sections 'cluster_a', 'uda_a' are read."))                                                        //nolint:lll //Reason: Wrapping this line would make editing difficult.
 
 	return f
 }
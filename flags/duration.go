@@ -0,0 +1,43 @@
+package flags
+
+import (
+	"strconv"
+	"time"
+)
+
+// millisecondsFlag is a time.Duration flag that also accepts a number without
+// a unit as milliseconds, e.g. timeout = 1000 in astools.conf, as the existing
+// tools do. Values with a unit are parsed by time.ParseDuration.
+type millisecondsFlag struct {
+	value *time.Duration
+}
+
+func newMillisecondsFlag(value *time.Duration, def time.Duration) *millisecondsFlag {
+	*value = def
+
+	return &millisecondsFlag{value: value}
+}
+
+func (flag *millisecondsFlag) Set(val string) error {
+	if ms, err := strconv.ParseInt(val, 10, 64); err == nil {
+		*flag.value = time.Duration(ms) * time.Millisecond
+		return nil
+	}
+
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return err
+	}
+
+	*flag.value = d
+
+	return nil
+}
+
+func (flag *millisecondsFlag) Type() string {
+	return "duration"
+}
+
+func (flag *millisecondsFlag) String() string {
+	return flag.value.String()
+}
@@ -1,8 +1,8 @@
 package flags
 
 import (
+	"bufio"
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 
@@ -10,15 +10,26 @@ import (
 )
 
 const (
-	DefaultPort = 3000
-	DefaultIPv4 = "127.0.0.1"
+	DefaultPort           = 3000
+	DefaultIPv4           = "127.0.0.1"
+	srvPrefix             = "srv:"
+	filePrefix            = "file:"
+	envPrefix             = "env:"
+	hostListCommentPrefix = "#"
 )
 
 // A cobra PFlag to parse and display help info for the host[:tls-name][:port]
 // input option.  It implements the pflag Value and SliceValue interfaces to
-// enable automatic parsing by cobra.
+// enable automatic parsing by cobra. Entries of the form
+// srv:name[:tls-name] are kept in SRV and only resolved into seeds using DNS
+// SRV records when connecting, see client.AerospikeConfig.SRVSeeds. The whole
+// list can also be read from a file or environment variable using the
+// file:<path> and env:<env-var> prefixes. The srv:, file: and env: prefixes
+// are reserved, a host named srv, file or env must be given with its port,
+// e.g. file:3000.
 type HostTLSPortSliceFlag struct {
 	Seeds      client.HostTLSPortSlice
+	SRV        []client.SRVSeed
 	useDefault bool
 }
 
@@ -31,71 +42,19 @@ func NewHostTLSPortSliceFlag() HostTLSPortSliceFlag {
 	}
 }
 
-func parseHostTLSPort(v string) (*client.HostTLSPort, error) {
-	host := &client.HostTLSPort{}
-	ipv6HostPattern := `^\[(?P<host>.*)\]`
-	hostPattern := `^(?P<host>[^:]*)` // matched ipv4 and hostname
-	tlsNamePattern := `(?P<tlsName>.*)`
-	portPattern := `(?P<port>\d+)$`
-	reIPv6Host := regexp.MustCompile(fmt.Sprintf("%s$", ipv6HostPattern))
-	reIPv6HostPort := regexp.MustCompile(fmt.Sprintf("%s:%s", ipv6HostPattern, portPattern))
-	reIPv6HostnamePort := regexp.MustCompile(fmt.Sprintf("%s:%s:%s", ipv6HostPattern, tlsNamePattern, portPattern))
-	reIPv4Host := regexp.MustCompile(fmt.Sprintf("%s$", hostPattern))
-	reIPv4HostPort := regexp.MustCompile(fmt.Sprintf("%s:%s", hostPattern, portPattern))
-	reIPv4HostnamePort := regexp.MustCompile(fmt.Sprintf("%s:%s:%s", hostPattern, tlsNamePattern, portPattern))
-
-	regexsAndNames := []struct {
-		regex      *regexp.Regexp
-		groupNames []string
-	}{
-		// The order is important since the ipv4 pattern also matches ipv6
-		{reIPv6HostnamePort, reIPv6HostnamePort.SubexpNames()},
-		{reIPv6HostPort, reIPv6HostPort.SubexpNames()},
-		{reIPv6Host, reIPv6Host.SubexpNames()},
-		{reIPv4HostnamePort, reIPv4HostnamePort.SubexpNames()},
-		{reIPv4HostPort, reIPv4HostPort.SubexpNames()},
-		{reIPv4Host, reIPv4Host.SubexpNames()},
-	}
+// Append adds the specified value to the end of the flag value list.
+func (slice *HostTLSPortSliceFlag) Append(val string) error {
+	val = strings.TrimSpace(val)
 
-	for _, r := range regexsAndNames {
-		regex := r.regex
-		groupNames := r.groupNames
-
-		if matchs := regex.FindStringSubmatch(v); matchs != nil {
-			for idx, match := range matchs {
-				var err error
-
-				name := groupNames[idx]
-
-				switch name {
-				case "host":
-					host.Host = match
-				case "tlsName":
-					host.TLSName = match
-				case "port":
-					var intPort int64
-
-					intPort, err = strconv.ParseInt(match, 0, 0)
-					if err == nil {
-						host.Port = int(intPort)
-					}
-				}
-
-				if err != nil {
-					return host, fmt.Errorf("failed to parse %s : %s", name, err)
-				}
-			}
+	if name, ok := strings.CutPrefix(val, srvPrefix); ok && !isPrefixedHost(val) {
+		srv := client.SRVSeed{}
+		srv.Name, srv.TLSName, _ = strings.Cut(name, ":")
+		slice.SRV = append(slice.SRV, srv)
 
-			return host, nil
-		}
+		return nil
 	}
 
-	return host, fmt.Errorf("does not match any expected formats")
-}
-
-// Append adds the specified value to the end of the flag value list.
-func (slice *HostTLSPortSliceFlag) Append(val string) error {
-	host, err := parseHostTLSPort(val)
+	host, err := client.ParseHostTLSPort(val)
 	if err != nil {
 		return err
 	}
@@ -105,9 +64,20 @@ func (slice *HostTLSPortSliceFlag) Append(val string) error {
 	return nil
 }
 
+// isPrefixedHost reports whether val, which starts with a reserved prefix, is
+// a host named like the prefix rather than a prefixed entry. Such hosts end
+// with a port, e.g. file:3000 or srv:tls-name:3000.
+func isPrefixedHost(val string) bool {
+	port := val[strings.LastIndex(val, ":")+1:]
+	_, err := strconv.Atoi(strings.TrimSpace(port))
+
+	return err == nil
+}
+
 // Replace will fully overwrite any data currently in the flag value list.
 func (slice *HostTLSPortSliceFlag) Replace(vals []string) error {
 	slice.Seeds = client.HostTLSPortSlice{}
+	slice.SRV = nil
 
 	for _, val := range vals {
 		if err := slice.Append(val); err != nil {
@@ -126,18 +96,40 @@ func (slice *HostTLSPortSliceFlag) GetSlice() []string {
 		strs = append(strs, elem.String())
 	}
 
+	for _, srv := range slice.SRV {
+		strs = append(strs, srv.String())
+	}
+
 	return strs
 }
 
 func (slice *HostTLSPortSliceFlag) Set(commaSepVal string) error {
-	vals := strings.Split(commaSepVal, ",")
-
 	if slice.useDefault {
 		slice.useDefault = false
-		return slice.Replace(vals)
+		slice.Seeds = client.HostTLSPortSlice{}
 	}
 
-	for _, val := range vals {
+	first, _, _ := strings.Cut(commaSepVal, ",")
+
+	if path, ok := strings.CutPrefix(commaSepVal, filePrefix); ok && !isPrefixedHost(first) {
+		data, err := readFromFile(path, false)
+		if err != nil {
+			return err
+		}
+
+		return slice.appendList(path, string(data))
+	}
+
+	if name, ok := strings.CutPrefix(commaSepVal, envPrefix); ok && !isPrefixedHost(first) {
+		data, err := fromEnv(name)
+		if err != nil {
+			return err
+		}
+
+		return slice.appendList("environment variable "+name, data)
+	}
+
+	for _, val := range strings.Split(commaSepVal, ",") {
 		if err := slice.Append(val); err != nil {
 			return err
 		}
@@ -146,8 +138,32 @@ func (slice *HostTLSPortSliceFlag) Set(commaSepVal string) error {
 	return nil
 }
 
+// appendList adds the hosts listed in data to the end of the flag value list.
+// Hosts are separated by commas or new lines, blank lines are skipped and
+// anything following a '#' is treated as a comment. Errors are prefixed with
+// the source and line number of the offending host.
+func (slice *HostTLSPortSliceFlag) appendList(source, data string) error {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line, _, _ := strings.Cut(scanner.Text(), hostListCommentPrefix)
+
+		for _, val := range strings.Split(line, ",") {
+			if strings.TrimSpace(val) == "" {
+				continue
+			}
+
+			if err := slice.Append(val); err != nil {
+				return fmt.Errorf("%s line %d: %w", source, lineNum, err)
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
 func (slice *HostTLSPortSliceFlag) Type() string {
-	return "host[:tls-name][:port][,...]"
+	return "host[:tls-name][:port]|srv:name[:tls-name][,...]"
 }
 
 func (slice *HostTLSPortSliceFlag) String() string {
@@ -156,5 +172,25 @@ func (slice *HostTLSPortSliceFlag) String() string {
 		return DefaultIPv4
 	}
 
-	return slice.Seeds.String()
+	if len(slice.SRV) == 0 {
+		return slice.Seeds.String()
+	}
+
+	strs := slice.GetSlice()
+
+	if len(strs) == 1 {
+		return strs[0]
+	}
+
+	return "[" + strings.Join(strs, ", ") + "]"
+}
+
+// MarshalJSON encodes the seeds in the host[:tls-name][:port] format.
+func (slice HostTLSPortSliceFlag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(slice)
+}
+
+// MarshalYAML encodes the seeds in the host[:tls-name][:port] format.
+func (slice HostTLSPortSliceFlag) MarshalYAML() (any, error) {
+	return slice.GetSlice(), nil
 }
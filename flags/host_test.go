@@ -1,6 +1,9 @@
 package flags
 
 import (
+	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
 
 	"github.com/aerospike/tools-common-go/client"
@@ -135,7 +138,7 @@ func (s *HostTestSuite) TestHostTLSPortSetGet() {
 					},
 				},
 			},
-			[]string{"2001:0db8:85a3:0000:0000:8a2e:0370:7334"},
+			[]string{"[2001:0db8:85a3:0000:0000:8a2e:0370:7334]"},
 		},
 		{
 			"[fe80::1ff:fe23:4567:890a]:3002",
@@ -148,7 +151,7 @@ func (s *HostTestSuite) TestHostTLSPortSetGet() {
 					},
 				},
 			},
-			[]string{"fe80::1ff:fe23:4567:890a:3002"},
+			[]string{"[fe80::1ff:fe23:4567:890a]:3002"},
 		},
 		{
 			"[100::]:tls-name:3003",
@@ -162,7 +165,7 @@ func (s *HostTestSuite) TestHostTLSPortSetGet() {
 					},
 				},
 			},
-			[]string{"100:::tls-name:3003"},
+			[]string{"[100::]:tls-name:3003"},
 		},
 	}
 
@@ -242,6 +245,130 @@ func (s *HostTestSuite) TestHostTLSPortString() {
 	}
 }
 
+func (s *HostTestSuite) TestHostTLSPortSetSRV() {
+	actual := NewHostTLSPortSliceFlag()
+
+	// SRV entries are not resolved until connecting.
+	s.NoError(actual.Set("127.0.0.1:3002,srv:_aerospike._tcp.cluster.example:tls-name"))
+	s.Equal(client.HostTLSPortSlice{{Host: "127.0.0.1", Port: 3002}}, actual.Seeds)
+	s.Equal([]client.SRVSeed{{Name: "_aerospike._tcp.cluster.example", TLSName: "tls-name"}}, actual.SRV)
+	s.Equal([]string{"127.0.0.1:3002", "srv:_aerospike._tcp.cluster.example:tls-name"}, actual.GetSlice())
+	s.Equal("[127.0.0.1:3002, srv:_aerospike._tcp.cluster.example:tls-name]", actual.String())
+
+	s.NoError(actual.Replace([]string{"srv:cluster.example"}))
+	s.Empty(actual.Seeds)
+	s.Equal([]client.SRVSeed{{Name: "cluster.example"}}, actual.SRV)
+	s.Equal("srv:cluster.example", actual.String())
+}
+
+func (s *HostTestSuite) TestHostTLSPortSetPrefixedHost() {
+	testCases := []struct {
+		input  string
+		output client.HostTLSPortSlice
+	}{
+		{
+			"srv:3000",
+			client.HostTLSPortSlice{{Host: "srv", Port: 3000}},
+		},
+		{
+			"srv:tls-name:3000",
+			client.HostTLSPortSlice{{Host: "srv", TLSName: "tls-name", Port: 3000}},
+		},
+		{
+			"file:3000,env:3001",
+			client.HostTLSPortSlice{{Host: "file", Port: 3000}, {Host: "env", Port: 3001}},
+		},
+		{
+			"env:tls-name:3000",
+			client.HostTLSPortSlice{{Host: "env", TLSName: "tls-name", Port: 3000}},
+		},
+	}
+
+	for _, tc := range testCases {
+		s.T().Run(tc.input, func(_ *testing.T) {
+			actual := NewHostTLSPortSliceFlag()
+
+			s.NoError(actual.Set(tc.input))
+			s.Equal(tc.output, actual.Seeds)
+			s.Empty(actual.SRV)
+		})
+	}
+}
+
+func (s *HostTestSuite) TestHostTLSPortSetSource() {
+	hostList := `# Seeds for the test cluster
+127.0.0.1:3000
+
+127.0.0.2:tls-name:3000, [::1]:3000 # local
+`
+	expected := []string{"127.0.0.1:3000", "127.0.0.2:tls-name:3000", "[::1]:3000"}
+	path := filepath.Join(s.T().TempDir(), "hosts")
+
+	s.Require().NoError(os.WriteFile(path, []byte(hostList), 0o600))
+	s.T().Setenv("FLAGS_TEST_HOSTS", hostList)
+
+	for _, input := range []string{"file:" + path, "env:FLAGS_TEST_HOSTS"} {
+		s.T().Run(input, func(_ *testing.T) {
+			actual := NewHostTLSPortSliceFlag()
+
+			s.NoError(actual.Set(input))
+			s.Equal(expected, actual.GetSlice())
+		})
+	}
+
+	s.Require().NoError(os.WriteFile(path, []byte("127.0.0.1\n\n127.0.0.1:65536\n"), 0o600))
+
+	actual := NewHostTLSPortSliceFlag()
+	err := actual.Set("file:" + path)
+
+	s.ErrorContains(err, path+" line 3: ")
+	s.ErrorIs(err, &client.ErrInvalidHost{Input: "127.0.0.1:65536"})
+	s.ErrorIs(actual.Set("file:"+filepath.Join(s.T().TempDir(), "missing")), os.ErrNotExist)
+	s.ErrorIs(actual.Set("env:FLAGS_TEST_HOSTS_MISSING"), ErrEnvironmentVariableNotFound)
+}
+
+func (s *HostTestSuite) TestHostTLSPortSetInvalid() {
+	testCases := []struct {
+		input  string
+		reason string
+		cause  error
+	}{
+		{
+			"127.0.0.1:tls-name:port",
+			"does not match any expected formats",
+			nil,
+		},
+		{
+			"127.0.0.1:99999999999999999999",
+			"failed to parse port",
+			strconv.ErrRange,
+		},
+		{
+			"127.0.0.1:65536",
+			"port must be between 1 and 65535",
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		s.T().Run(tc.input, func(_ *testing.T) {
+			actual := NewHostTLSPortSliceFlag()
+			err := actual.Set(tc.input)
+
+			var hostErr *client.ErrInvalidHost
+
+			s.Require().ErrorAs(err, &hostErr)
+			s.Equal(tc.input, hostErr.Input)
+			s.Equal(tc.reason, hostErr.Reason)
+			s.ErrorIs(err, &client.ErrInvalidHost{})
+
+			if tc.cause != nil {
+				s.ErrorIs(err, tc.cause)
+			}
+		})
+	}
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestRunHostTestSuite(t *testing.T) {
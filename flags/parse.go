@@ -17,12 +17,13 @@ const (
 
 var (
 	ErrEnvironmentVariableNotFound = fmt.Errorf("environment variable not found")
+	ErrUnsupportedSourcePrefix     = fmt.Errorf("prefix not supported")
 )
 
 func fromEnv(v string) (string, error) {
 	result := os.Getenv(v)
 	if result == "" {
-		return "", ErrEnvironmentVariableNotFound
+		return "", fmt.Errorf("%w: %s", ErrEnvironmentVariableNotFound, v)
 	}
 
 	return result, nil
@@ -57,7 +58,7 @@ func flagFormatParser(val string, mode flagFormat) (string, error) {
 			return fromEnv(name)
 		}
 
-		return "", fmt.Errorf("\"env:\" prefix not supported")
+		return "", fmt.Errorf("\"env:\" %w", ErrUnsupportedSourcePrefix)
 	case "env-b64":
 		if (mode & flagFormatEnvB64) != 0 {
 			b64Val, err := fromEnv(name)
@@ -68,19 +69,19 @@ func flagFormatParser(val string, mode flagFormat) (string, error) {
 			return fromBase64(b64Val)
 		}
 
-		return "", fmt.Errorf("\"env-b64:\" prefix not supported")
+		return "", fmt.Errorf("\"env-b64:\" %w", ErrUnsupportedSourcePrefix)
 	case "b64":
 		if (mode & flagFormatB64) != 0 {
 			return fromBase64(name)
 		}
 
-		return "", fmt.Errorf("\"b64:\" prefix not supported")
+		return "", fmt.Errorf("\"b64:\" %w", ErrUnsupportedSourcePrefix)
 	case "file":
 		if (mode & flagFormatFile) != 0 {
 			return fromFile(name)
 		}
 
-		return "", fmt.Errorf("\"file:\" prefix not supported")
+		return "", fmt.Errorf("\"file:\" %w", ErrUnsupportedSourcePrefix)
 	}
 
 	return "", nil
@@ -2,6 +2,7 @@ package flags
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"testing"
 )
@@ -49,6 +50,7 @@ func Test_flagFormatParser(t *testing.T) {
 		args    args
 		want    string
 		wantErr bool
+		errIs   error
 	}{
 		{
 			name: "t1",
@@ -67,6 +69,7 @@ func Test_flagFormatParser(t *testing.T) {
 			},
 			want:    "",
 			wantErr: true,
+			errIs:   ErrEnvironmentVariableNotFound,
 		},
 		{
 			name: "t2",
@@ -76,6 +79,7 @@ func Test_flagFormatParser(t *testing.T) {
 			},
 			want:    "",
 			wantErr: true,
+			errIs:   ErrUnsupportedSourcePrefix,
 		},
 		{
 			name: "t3",
@@ -94,6 +98,7 @@ func Test_flagFormatParser(t *testing.T) {
 			},
 			want:    "",
 			wantErr: true,
+			errIs:   ErrEnvironmentVariableNotFound,
 		},
 		{
 			name: "t5",
@@ -139,6 +144,7 @@ func Test_flagFormatParser(t *testing.T) {
 			},
 			want:    "",
 			wantErr: true,
+			errIs:   ErrUnsupportedSourcePrefix,
 		},
 		{
 			name: "t8",
@@ -157,6 +163,7 @@ func Test_flagFormatParser(t *testing.T) {
 			},
 			want:    "",
 			wantErr: true,
+			errIs:   os.ErrNotExist,
 		},
 		{
 			name: "t10",
@@ -166,6 +173,7 @@ func Test_flagFormatParser(t *testing.T) {
 			},
 			want:    "",
 			wantErr: true,
+			errIs:   ErrUnsupportedSourcePrefix,
 		},
 		{
 			name: "t11",
@@ -187,6 +195,10 @@ func Test_flagFormatParser(t *testing.T) {
 				return
 			}
 
+			if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+				t.Errorf("flagFormatParser() error = %v, want %v", err, tt.errIs)
+			}
+
 			if got != tt.want {
 				t.Errorf("flagFormatParser() = %v, want %v", got, tt.want)
 			}
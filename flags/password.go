@@ -1,5 +1,52 @@
 package flags
 
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aerospike/tools-common-go/client"
+	"golang.org/x/term"
+)
+
+// passwordPromptValue is the NoOptDefVal of the password flags. pflag passes
+// it to Set for a flag given without a value, which then prompts for the
+// password. It is shown in the help output as --password[=<prompt>] so it can
+// not contain a NUL byte, which pflag uses to align the help output. Giving it
+// as a value, e.g. password = "<prompt>" in a config file, prompts as well.
+const passwordPromptValue = "<prompt>"
+
+var ErrNotTerminal = fmt.Errorf("stdin is not a terminal")
+
+// Prompter shows prompt to the user and returns the password they entered.
+type Prompter func(prompt string) ([]byte, error)
+
+// TerminalPrompter writes prompt to stderr and reads the password from stdin
+// without echoing it. It returns ErrNotTerminal if stdin is not a terminal.
+func TerminalPrompter(prompt string) ([]byte, error) {
+	return promptTerminal(os.Stdin, os.Stderr, prompt)
+}
+
+func promptTerminal(in *os.File, out io.Writer, prompt string) ([]byte, error) {
+	fd := int(in.Fd()) //nolint:gosec // File descriptors fit in an int.
+	if !term.IsTerminal(fd) {
+		return nil, ErrNotTerminal
+	}
+
+	fmt.Fprint(out, prompt)
+
+	password, err := term.ReadPassword(fd)
+
+	// The new line typed by the user is not echoed either.
+	fmt.Fprintln(out)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return password, nil
+}
+
 // PasswordFlag defines a Cobra compatible
 // flag for password related options.
 // examples include
@@ -26,6 +73,70 @@ func (flag *PasswordFlag) Type() string {
 	return "\"env-b64:<env-var>,b64:<b64-pass>,file:<pass-file>,<clear-pass>\""
 }
 
+// String returns the password redacted so that it never shows up in help
+// output or logs. Use Value to get the password itself.
 func (flag *PasswordFlag) String() string {
-	return string(*flag)
+	if len(*flag) == 0 {
+		return ""
+	}
+
+	return client.RedactedSecret
+}
+
+// Value returns the password in clear text.
+func (flag *PasswordFlag) Value() []byte {
+	return *flag
+}
+
+// MarshalJSON encodes the password redacted.
+func (flag PasswordFlag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(flag)
+}
+
+// MarshalYAML encodes the password redacted.
+func (flag PasswordFlag) MarshalYAML() (any, error) {
+	if len(flag) == 0 {
+		return "", nil
+	}
+
+	return client.RedactedSecret, nil
+}
+
+// promptPasswordFlag wraps a PasswordFlag so that it prompts for the password
+// when the flag is given without a value. The prompter is read when the flag
+// is set so that it can be changed after the flag set is created. A value must
+// be given with =, e.g. --password=<password>, since pflag does not take the
+// next argument as the value of a flag with a NoOptDefVal.
+type promptPasswordFlag struct {
+	*PasswordFlag
+	prompter *Prompter
+	prompt   string
+}
+
+func newPromptPasswordFlag(value *PasswordFlag, prompter *Prompter, prompt string) *promptPasswordFlag {
+	return &promptPasswordFlag{
+		PasswordFlag: value,
+		prompter:     prompter,
+		prompt:       prompt,
+	}
+}
+
+func (flag *promptPasswordFlag) Set(val string) error {
+	if val != passwordPromptValue {
+		return flag.PasswordFlag.Set(val)
+	}
+
+	prompter := *flag.prompter
+	if prompter == nil {
+		prompter = TerminalPrompter
+	}
+
+	password, err := prompter(flag.prompt)
+	if err != nil {
+		return fmt.Errorf("failed to prompt for the password: %w", err)
+	}
+
+	*flag.PasswordFlag = password
+
+	return nil
 }
@@ -2,9 +2,14 @@ package flags
 
 import (
 	"bytes"
+	"errors"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/aerospike/tools-common-go/config"
 )
 
 func TestPassword(t *testing.T) {
@@ -106,3 +111,167 @@ func TestPassword(t *testing.T) {
 		})
 	}
 }
+
+func TestPasswordString(t *testing.T) {
+	flag := PasswordFlag{}
+
+	if actual := flag.String(); actual != "" {
+		t.Errorf("String() = %q, want empty", actual)
+	}
+
+	flag = PasswordFlag("secret")
+
+	if actual := flag.String(); actual != "xxxxx" {
+		t.Errorf("String() = %q, want it redacted", actual)
+	}
+
+	if actual := flag.Value(); string(actual) != "secret" {
+		t.Errorf("Value() = %q, want %q", actual, "secret")
+	}
+}
+
+func TestPasswordPrompt(t *testing.T) {
+	errPrompt := errors.New("prompt failed")
+
+	testCases := []struct {
+		name            string
+		args            []string
+		promptErr       error
+		password        PasswordFlag
+		keyFilePassword PasswordFlag
+		prompts         []string
+		remaining       []string
+	}{
+		{
+			name:            "BareFlags",
+			args:            []string{"-P", "--tls-keyfile-password", "--user", "admin"},
+			password:        PasswordFlag("entered"),
+			keyFilePassword: PasswordFlag("entered"),
+			prompts:         []string{"Enter password: ", "Enter TLS key file password: "},
+		},
+		{
+			name:     "Values",
+			args:     []string{"-P=secret", "--tls-keyfile-password=key-pass"},
+			password: PasswordFlag("secret"),
+			// The key file password is not prompted for.
+			keyFilePassword: PasswordFlag("key-pass"),
+		},
+		{
+			// Values must be given with =, the next argument is not the value.
+			name:      "SeparateValues",
+			args:      []string{"--tls-keyfile-password", "-P", "secret"},
+			password:  PasswordFlag("entered"),
+			prompts:   []string{"Enter TLS key file password: ", "Enter password: "},
+			remaining: []string{"secret"},
+			// The bare --tls-keyfile-password prompts too.
+			keyFilePassword: PasswordFlag("entered"),
+		},
+		{
+			name:            "PromptValue",
+			args:            []string{"--password=<prompt>", "--tls-keyfile-password=prompt:"},
+			password:        PasswordFlag("entered"),
+			keyFilePassword: PasswordFlag("prompt:"),
+			prompts:         []string{"Enter password: "},
+		},
+		{
+			name:      "PromptError",
+			args:      []string{"--password"},
+			promptErr: errPrompt,
+			prompts:   []string{"Enter password: "},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var prompts []string
+
+			af := NewDefaultAerospikeFlags()
+			flagSet := af.NewFlagSet(DefaultWrapHelpString)
+
+			af.Prompter = func(prompt string) ([]byte, error) {
+				prompts = append(prompts, prompt)
+				return []byte("entered"), tc.promptErr
+			}
+
+			err := flagSet.Parse(tc.args)
+			if !errors.Is(err, tc.promptErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tc.promptErr)
+			}
+
+			if !reflect.DeepEqual(af.Password, tc.password) ||
+				!reflect.DeepEqual(af.TLSKeyFilePass, tc.keyFilePassword) {
+				t.Errorf("Parse() set passwords %q and %q, want %q and %q",
+					af.Password, af.TLSKeyFilePass, tc.password, tc.keyFilePassword)
+			}
+
+			if !reflect.DeepEqual(prompts, tc.prompts) {
+				t.Errorf("Parse() prompted %q, want %q", prompts, tc.prompts)
+			}
+
+			if tc.promptErr == nil && !reflect.DeepEqual(flagSet.Args(), append([]string{}, tc.remaining...)) {
+				t.Errorf("Parse() left arguments %q, want %q", flagSet.Args(), tc.remaining)
+			}
+		})
+	}
+}
+
+func TestPasswordPromptConfigFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "astools.conf")
+
+	err := os.WriteFile(configPath, []byte("[cluster]\npassword = \"<prompt>\"\n"), 0o600)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var prompts []string
+
+	af := NewDefaultAerospikeFlags()
+	flagSet := af.NewFlagSet(DefaultWrapHelpString)
+	loader := config.NewLoader()
+
+	af.Prompter = func(prompt string) ([]byte, error) {
+		prompts = append(prompts, prompt)
+		return []byte("entered"), nil
+	}
+
+	loader.SetConfDirs([]string{t.TempDir()})
+	loader.BindPFlags(flagSet, "cluster")
+
+	if _, err := loader.InitConfig(configPath, "", flagSet); err != nil {
+		t.Fatalf("InitConfig() returned an unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(af.Password, PasswordFlag("entered")) ||
+		!reflect.DeepEqual(prompts, []string{"Enter password: "}) {
+		t.Errorf("InitConfig() set password %q after prompting %q, want the entered password", af.Password, prompts)
+	}
+}
+
+func TestPasswordPromptUsage(t *testing.T) {
+	usage := NewDefaultAerospikeFlags().NewFlagSet(DefaultWrapHelpString).FlagUsages()
+
+	// pflag aligns the help output on a NUL byte, so the prompt value must not
+	// contain one.
+	if !strings.Contains(usage, `<clear-pass>"[=<prompt>]`) || strings.Contains(usage, "\x00") {
+		t.Errorf("FlagUsages() = %q, want the password flags shown with [=<prompt>]", usage)
+	}
+}
+
+func TestPromptTerminalNotTerminal(t *testing.T) {
+	in, err := os.Open(testFileDataPath)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	defer in.Close()
+
+	out := &bytes.Buffer{}
+
+	if _, err := promptTerminal(in, out, "Enter password: "); !errors.Is(err, ErrNotTerminal) {
+		t.Errorf("promptTerminal() error = %v, want %v", err, ErrNotTerminal)
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("promptTerminal() wrote %q, want no prompt", out.String())
+	}
+}
@@ -0,0 +1,391 @@
+package flags
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TLSCipherSuitesFlag defines a Cobra compatible flag
+// for selecting the TLS cipher suites offered to the server.
+// The format is the same as OpenSSL's cipher list format which is
+// what the Aerospike server uses for its cipher-suite option. The insecure
+// RC4 and 3DES cipher suites are only selected by name, never by an alias
+// such as ALL or MEDIUM.
+// Example flags include.
+// --tls-cipher-suite
+type TLSCipherSuitesFlag []uint16
+
+var (
+	ErrCipherSuiteNotSupported = fmt.Errorf("cipher suite not supported by crypto/tls")
+	ErrUnknownCipherSuite      = fmt.Errorf("unknown cipher suite")
+)
+
+type cipherSuite struct {
+	openSSL  string
+	strength string // HIGH or MEDIUM
+	kx       string // Key exchange: ECDHE or RSA
+	au       string // Authentication: ECDSA or RSA
+	enc      string
+	mac      string
+	id       uint16
+}
+
+// insecure reports whether the cipher suite uses the broken RC4 or 3DES
+// ciphers.
+func (cs cipherSuite) insecure() bool {
+	return cs.enc == "RC4" || cs.enc == "3DES"
+}
+
+// aliases returns the OpenSSL cipher strings that select the cipher suite.
+func (cs cipherSuite) aliases() []string {
+	aliases := []string{cs.strength}
+
+	switch cs.kx {
+	case "ECDHE":
+		aliases = append(aliases, "ECDHE", "EECDH", "kECDHE", "kEECDH")
+	case "RSA":
+		aliases = append(aliases, "RSA", "kRSA")
+	}
+
+	switch cs.au {
+	case "ECDSA":
+		aliases = append(aliases, "ECDSA", "aECDSA")
+	case "RSA":
+		aliases = append(aliases, "aRSA")
+	}
+
+	switch cs.enc {
+	case "AES128GCM", "AES256GCM":
+		aliases = append(aliases, "AES", cs.enc[:6], "AESGCM")
+	case "AES128", "AES256":
+		aliases = append(aliases, "AES", cs.enc)
+	default:
+		aliases = append(aliases, cs.enc)
+	}
+
+	if cs.mac == "SHA1" {
+		return append(aliases, "SHA1", "SHA", "TLSv1")
+	}
+
+	return append(aliases, cs.mac, "TLSv1.2")
+}
+
+// cipherSuites are the TLS 1.0-1.2 cipher suites implemented by crypto/tls.
+// TLS 1.3 cipher suites are not configurable in Go.
+var cipherSuites = []cipherSuite{
+	{
+		"ECDHE-ECDSA-AES256-GCM-SHA384", "HIGH", "ECDHE", "ECDSA", "AES256GCM", "SHA384",
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	},
+	{
+		"ECDHE-RSA-AES256-GCM-SHA384", "HIGH", "ECDHE", "RSA", "AES256GCM", "SHA384",
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	},
+	{
+		"ECDHE-ECDSA-CHACHA20-POLY1305", "HIGH", "ECDHE", "ECDSA", "CHACHA20", "SHA256",
+		tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	},
+	{
+		"ECDHE-RSA-CHACHA20-POLY1305", "HIGH", "ECDHE", "RSA", "CHACHA20", "SHA256",
+		tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+	},
+	{
+		"ECDHE-ECDSA-AES128-GCM-SHA256", "HIGH", "ECDHE", "ECDSA", "AES128GCM", "SHA256",
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	},
+	{
+		"ECDHE-RSA-AES128-GCM-SHA256", "HIGH", "ECDHE", "RSA", "AES128GCM", "SHA256",
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	},
+	{
+		"ECDHE-ECDSA-AES128-SHA256", "HIGH", "ECDHE", "ECDSA", "AES128", "SHA256",
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
+	},
+	{
+		"ECDHE-RSA-AES128-SHA256", "HIGH", "ECDHE", "RSA", "AES128", "SHA256",
+		tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,
+	},
+	{
+		"ECDHE-ECDSA-AES256-SHA", "HIGH", "ECDHE", "ECDSA", "AES256", "SHA1",
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+	},
+	{
+		"ECDHE-RSA-AES256-SHA", "HIGH", "ECDHE", "RSA", "AES256", "SHA1",
+		tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+	},
+	{
+		"ECDHE-ECDSA-AES128-SHA", "HIGH", "ECDHE", "ECDSA", "AES128", "SHA1",
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	},
+	{
+		"ECDHE-RSA-AES128-SHA", "HIGH", "ECDHE", "RSA", "AES128", "SHA1",
+		tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	},
+	{
+		"AES256-GCM-SHA384", "HIGH", "RSA", "RSA", "AES256GCM", "SHA384",
+		tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+	},
+	{
+		"AES128-GCM-SHA256", "HIGH", "RSA", "RSA", "AES128GCM", "SHA256",
+		tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	},
+	{
+		"AES128-SHA256", "HIGH", "RSA", "RSA", "AES128", "SHA256",
+		tls.TLS_RSA_WITH_AES_128_CBC_SHA256,
+	},
+	{
+		"AES256-SHA", "HIGH", "RSA", "RSA", "AES256", "SHA1",
+		tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	},
+	{
+		"AES128-SHA", "HIGH", "RSA", "RSA", "AES128", "SHA1",
+		tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	},
+	{
+		"ECDHE-RSA-DES-CBC3-SHA", "MEDIUM", "ECDHE", "RSA", "3DES", "SHA1",
+		tls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA,
+	},
+	{
+		"DES-CBC3-SHA", "MEDIUM", "RSA", "RSA", "3DES", "SHA1",
+		tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA,
+	},
+	{
+		"ECDHE-ECDSA-RC4-SHA", "MEDIUM", "ECDHE", "ECDSA", "RC4", "SHA1",
+		tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA,
+	},
+	{
+		"ECDHE-RSA-RC4-SHA", "MEDIUM", "ECDHE", "RSA", "RC4", "SHA1",
+		tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA,
+	},
+	{
+		"RC4-SHA", "MEDIUM", "RSA", "RSA", "RC4", "SHA1",
+		tls.TLS_RSA_WITH_RC4_128_SHA,
+	},
+}
+
+// emptyCipherAliases are OpenSSL cipher strings that are valid but do not
+// select any cipher suite implemented by Go. e.g. "!aNULL" is commonly found
+// in server cipher lists.
+var emptyCipherAliases = []string{
+	"eNULL", "NULL", "aNULL", "COMPLEMENTOFALL", "EXPORT", "EXP", "LOW", "DES", "RC2", "IDEA", "SEED", "MD5",
+	"PSK", "kPSK", "aPSK", "SRP", "kSRP", "aSRP", "DSS", "aDSS", "DH", "EDH", "DHE", "kDHE", "kEDH", "ADH",
+	"AECDH", "CAMELLIA", "ARIA", "CCM", "CCM8", "GOST", "SSLv3",
+}
+
+// unsupportedCipherPrefixes and unsupportedCipherFragments match OpenSSL and
+// IANA names of cipher suites that exist but are not implemented by Go.
+var unsupportedCipherPrefixes = []string{
+	"DHE-", "EDH-", "ADH-", "AECDH-", "ECDH-", "PSK-", "SRP-", "DSS-", "EXP-", "DES-CBC-",
+	"TLS_DHE_", "TLS_DH_", "TLS_ECDH_", "TLS_PSK_", "TLS_SRP_",
+}
+
+var unsupportedCipherFragments = []string{
+	"CAMELLIA", "ARIA", "CCM", "SEED", "IDEA", "NULL",
+}
+
+// tls13CipherSuites cannot be configured since crypto/tls always enables them
+// when TLS 1.3 is negotiated.
+var tls13CipherSuites = []string{
+	"TLS_AES_128_GCM_SHA256", "TLS_AES_256_GCM_SHA384", "TLS_CHACHA20_POLY1305_SHA256",
+	"TLS_AES_128_CCM_SHA256", "TLS_AES_128_CCM_8_SHA256",
+}
+
+// lookupCipherSuites returns the cipher suites matching an OpenSSL cipher
+// string. A string may be an OpenSSL name, an IANA name, an alias, or aliases
+// joined with "+" to select their intersection e.g. "ECDHE+AESGCM".
+func lookupCipherSuites(val string) ([]uint16, error) {
+	if val == "ALL" {
+		return cipherSuiteIDs(func(_ cipherSuite) bool { return true }), nil
+	}
+
+	if val == "DEFAULT" {
+		return cipherSuiteIDs(isDefaultCipherSuite), nil
+	}
+
+	if val == "COMPLEMENTOFDEFAULT" {
+		return cipherSuiteIDs(func(cs cipherSuite) bool { return !isDefaultCipherSuite(cs) }), nil
+	}
+
+	for _, cs := range cipherSuites {
+		if cs.openSSL == val || tls.CipherSuiteName(cs.id) == val {
+			return []uint16{cs.id}, nil
+		}
+	}
+
+	if slices.Contains(tls13CipherSuites, val) {
+		return nil, fmt.Errorf("%w: %s, TLS 1.3 cipher suites are not configurable", ErrCipherSuiteNotSupported, val)
+	}
+
+	if slices.Contains(emptyCipherAliases, val) {
+		return nil, nil
+	}
+
+	aliases := strings.Split(val, "+")
+	known := false
+
+	for _, alias := range aliases {
+		known = slices.ContainsFunc(cipherSuites, func(cs cipherSuite) bool {
+			return slices.Contains(cs.aliases(), alias)
+		}) || slices.Contains(emptyCipherAliases, alias)
+
+		if !known {
+			break
+		}
+	}
+
+	if known {
+		return cipherSuiteIDs(func(cs cipherSuite) bool {
+			for _, alias := range aliases {
+				if !slices.Contains(cs.aliases(), alias) {
+					return false
+				}
+			}
+
+			return true
+		}), nil
+	}
+
+	for _, prefix := range unsupportedCipherPrefixes {
+		if strings.HasPrefix(val, prefix) {
+			return nil, fmt.Errorf("%w: %s", ErrCipherSuiteNotSupported, val)
+		}
+	}
+
+	for _, fragment := range unsupportedCipherFragments {
+		if strings.Contains(val, fragment) {
+			return nil, fmt.Errorf("%w: %s", ErrCipherSuiteNotSupported, val)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownCipherSuite, val)
+}
+
+func isDefaultCipherSuite(cs cipherSuite) bool {
+	return slices.ContainsFunc(tls.CipherSuites(), func(s *tls.CipherSuite) bool {
+		return s.ID == cs.id
+	})
+}
+
+// cipherSuiteIDs returns the cipher suites selected by an alias. Insecure
+// cipher suites are left out.
+func cipherSuiteIDs(match func(cipherSuite) bool) []uint16 {
+	ids := []uint16{}
+
+	for _, cs := range cipherSuites {
+		if !cs.insecure() && match(cs) {
+			ids = append(ids, cs.id)
+		}
+	}
+
+	return ids
+}
+
+func (flag *TLSCipherSuitesFlag) Set(val string) error {
+	tokens := strings.FieldsFunc(val, func(r rune) bool {
+		return r == ':' || r == ',' || r == ' '
+	})
+
+	if len(tokens) == 0 {
+		*flag = nil
+		return nil
+	}
+
+	selected := []uint16{}
+	banned := []uint16{}
+
+	for _, tok := range tokens {
+		var op byte
+
+		if tok[0] == '!' || tok[0] == '+' || tok[0] == '-' {
+			op = tok[0]
+			tok = tok[1:]
+		}
+
+		if strings.HasPrefix(tok, "@") {
+			// @STRENGTH and @SECLEVEL have no effect since Go orders the
+			// cipher suites itself.
+			continue
+		}
+
+		suites, err := lookupCipherSuites(tok)
+		if err != nil {
+			if op == '!' || op == '-' {
+				// Removing a cipher suite Go does not support is a no-op.
+				if errors.Is(err, ErrCipherSuiteNotSupported) {
+					continue
+				}
+			}
+
+			return err
+		}
+
+		switch op {
+		case '!':
+			banned = append(banned, suites...)
+			selected = slices.DeleteFunc(selected, func(id uint16) bool {
+				return slices.Contains(suites, id)
+			})
+		case '-':
+			selected = slices.DeleteFunc(selected, func(id uint16) bool {
+				return slices.Contains(suites, id)
+			})
+		case '+':
+			moved := []uint16{}
+			selected = slices.DeleteFunc(selected, func(id uint16) bool {
+				if slices.Contains(suites, id) {
+					moved = append(moved, id)
+					return true
+				}
+
+				return false
+			})
+			selected = append(selected, moved...)
+		default:
+			for _, id := range suites {
+				if !slices.Contains(selected, id) && !slices.Contains(banned, id) {
+					selected = append(selected, id)
+				}
+			}
+		}
+	}
+
+	if len(selected) == 0 {
+		return fmt.Errorf("no supported cipher suites selected by %q", val)
+	}
+
+	*flag = selected
+
+	return nil
+}
+
+func (flag *TLSCipherSuitesFlag) Type() string {
+	return "\"[!|+|-]<cipher-suite|alias>[:...]\""
+}
+
+func (flag *TLSCipherSuitesFlag) String() string {
+	names := []string{}
+
+	for _, id := range *flag {
+		for _, cs := range cipherSuites {
+			if cs.id == id {
+				names = append(names, cs.openSSL)
+				break
+			}
+		}
+	}
+
+	return strings.Join(names, ":")
+}
+
+// MarshalJSON encodes the cipher suites by their OpenSSL names.
+func (flag TLSCipherSuitesFlag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(flag)
+}
+
+// MarshalYAML encodes the cipher suites by their OpenSSL names.
+func (flag TLSCipherSuitesFlag) MarshalYAML() (any, error) {
+	return flag.String(), nil
+}
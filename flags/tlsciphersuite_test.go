@@ -0,0 +1,176 @@
+package flags
+
+import (
+	"crypto/tls"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TLSCipherSuitesTestSuite struct {
+	suite.Suite
+}
+
+func (s *TLSCipherSuitesTestSuite) TestTLSCipherSuitesFlag() {
+	testCases := []struct {
+		input  string
+		output TLSCipherSuitesFlag
+	}{
+		{
+			"",
+			nil,
+		},
+		{
+			"ECDHE-RSA-AES256-GCM-SHA384",
+			TLSCipherSuitesFlag{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+		},
+		{
+			"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384:ECDHE-RSA-AES128-GCM-SHA256",
+			TLSCipherSuitesFlag{
+				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			},
+		},
+		{
+			"ECDHE-RSA-AES256-GCM-SHA384,ECDHE-RSA-AES256-GCM-SHA384 ECDHE-RSA-AES128-GCM-SHA256",
+			TLSCipherSuitesFlag{
+				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			},
+		},
+		{
+			"ECDHE+AESGCM:!ECDSA",
+			TLSCipherSuitesFlag{
+				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			},
+		},
+		{
+			"ECDHE-RSA-AES128-GCM-SHA256:ECDHE-RSA-AES256-GCM-SHA384:+AES128",
+			TLSCipherSuitesFlag{
+				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			},
+		},
+		{
+			"aRSA+AESGCM:-kRSA:AES256-GCM-SHA384",
+			TLSCipherSuitesFlag{
+				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+				tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+			},
+		},
+		{
+			"!kRSA:aRSA+AESGCM:AES256-GCM-SHA384",
+			TLSCipherSuitesFlag{
+				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			},
+		},
+		{
+			"HIGH:!aNULL:!MD5:!SHA1:!SHA256:!kRSA:!DHE-RSA-AES256-GCM-SHA384:@STRENGTH",
+			TLSCipherSuitesFlag{
+				tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		s.T().Run(tc.input, func(_ *testing.T) {
+			var actual TLSCipherSuitesFlag
+
+			s.NoError(actual.Set(tc.input))
+			s.Equal(tc.output, actual)
+		})
+	}
+}
+
+func (s *TLSCipherSuitesTestSuite) TestTLSCipherSuitesFlagAliases() {
+	var all, high, def TLSCipherSuitesFlag
+
+	s.NoError(all.Set("ALL"))
+	s.NoError(high.Set("HIGH"))
+	s.NoError(def.Set("DEFAULT"))
+
+	s.Len(all, len(cipherSuites)-5)
+	s.NotContains(high, uint16(tls.TLS_RSA_WITH_RC4_128_SHA))
+	s.NotContains(high, uint16(tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA))
+	s.NotContains(def, uint16(tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA))
+	s.Contains(def, uint16(tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384))
+}
+
+func (s *TLSCipherSuitesTestSuite) TestTLSCipherSuitesFlagInsecure() {
+	for _, alias := range []string{"ALL", "RSA", "SHA1", "COMPLEMENTOFDEFAULT", "ALL:MEDIUM:RC4:3DES"} {
+		s.T().Run(alias, func(_ *testing.T) {
+			var actual TLSCipherSuitesFlag
+
+			s.NoError(actual.Set(alias))
+
+			for _, cs := range cipherSuites {
+				if cs.insecure() {
+					s.NotContains(actual, cs.id)
+				}
+			}
+		})
+	}
+
+	var actual TLSCipherSuitesFlag
+
+	s.Error(actual.Set("MEDIUM"))
+	s.NoError(actual.Set("ECDHE-RSA-AES128-GCM-SHA256:RC4-SHA:TLS_RSA_WITH_3DES_EDE_CBC_SHA"))
+	s.Equal(TLSCipherSuitesFlag{
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_RSA_WITH_RC4_128_SHA,
+		tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA,
+	}, actual)
+}
+
+func (s *TLSCipherSuitesTestSuite) TestTLSCipherSuitesFlagErrors() {
+	testCases := []struct {
+		input        string
+		notSupported bool
+	}{
+		{"DHE-RSA-AES256-GCM-SHA384", true},
+		{"TLS_DHE_RSA_WITH_AES_256_GCM_SHA384", true},
+		{"TLS_AES_128_GCM_SHA256", true},
+		{"ECDHE-RSA-CAMELLIA256-SHA384", true},
+		{"ECDHE-RSA-AES256-GCM-SHA38", false},
+		{"HIGHER", false},
+		{"!ECDHE-RSA-AES256-GCM-SHA38", false},
+		{"aNULL", false},
+		{"HIGH:!HIGH", false},
+	}
+
+	for _, tc := range testCases {
+		s.T().Run(tc.input, func(_ *testing.T) {
+			var actual TLSCipherSuitesFlag
+
+			err := actual.Set(tc.input)
+
+			s.Error(err)
+			s.Equal(tc.notSupported, errors.Is(err, ErrCipherSuiteNotSupported))
+		})
+	}
+}
+
+func (s *TLSCipherSuitesTestSuite) TestTLSCipherSuitesFlagString() {
+	flag := TLSCipherSuitesFlag{
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	}
+
+	s.Equal("ECDHE-RSA-AES256-GCM-SHA384:AES128-SHA", flag.String())
+
+	var actual TLSCipherSuitesFlag
+
+	s.NoError(actual.Set(flag.String()))
+	s.Equal(flag, actual)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestRunTLSCipherSuitesTestSuite(t *testing.T) {
+	suite.Run(t, new(TLSCipherSuitesTestSuite))
+}
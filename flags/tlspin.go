@@ -0,0 +1,81 @@
+package flags
+
+import (
+	"strings"
+
+	"github.com/aerospike/tools-common-go/client"
+)
+
+// TLSPinsFlag defines a Cobra compatible flag for
+// pinning server certificates. It implements the pflag Value and SliceValue
+// interfaces.
+// --tls-pin
+type TLSPinsFlag []client.TLSPin
+
+// Append adds the specified value to the end of the flag value list.
+func (slice *TLSPinsFlag) Append(val string) error {
+	pin, err := client.ParseTLSPin(strings.TrimSpace(val))
+	if err != nil {
+		return err
+	}
+
+	*slice = append(*slice, pin)
+
+	return nil
+}
+
+// Replace will fully overwrite any data currently in the flag value list.
+func (slice *TLSPinsFlag) Replace(vals []string) error {
+	*slice = TLSPinsFlag{}
+
+	for _, val := range vals {
+		if err := slice.Append(val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetSlice returns the flag value list as an array of strings.
+func (slice *TLSPinsFlag) GetSlice() []string {
+	strs := []string{}
+
+	for _, pin := range *slice {
+		strs = append(strs, pin.String())
+	}
+
+	return strs
+}
+
+func (slice *TLSPinsFlag) Set(commaSepVal string) error {
+	if commaSepVal == "" {
+		return nil
+	}
+
+	for _, val := range strings.Split(commaSepVal, ",") {
+		if err := slice.Append(val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (slice *TLSPinsFlag) Type() string {
+	return "sha256/<base64-spki-hash>|<hex-cert-fingerprint>[,...]"
+}
+
+func (slice *TLSPinsFlag) String() string {
+	return strings.Join(slice.GetSlice(), ",")
+}
+
+// MarshalJSON encodes the pins in the --tls-pin format.
+func (slice TLSPinsFlag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(slice)
+}
+
+// MarshalYAML encodes the pins in the --tls-pin format.
+func (slice TLSPinsFlag) MarshalYAML() (any, error) {
+	return slice.GetSlice(), nil
+}
@@ -0,0 +1,53 @@
+package flags
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aerospike/tools-common-go/client"
+	"github.com/stretchr/testify/suite"
+)
+
+type TLSPinsTestSuite struct {
+	suite.Suite
+}
+
+func (s *TLSPinsTestSuite) TestTLSPinsFlag() {
+	spkiPin := client.TLSPin{Type: client.TLSPinSPKI, Hash: [32]byte{1}}
+	certPin := client.TLSPin{Type: client.TLSPinCertificate, Hash: [32]byte{2}}
+
+	var actual TLSPinsFlag
+
+	s.NoError(actual.Set(spkiPin.String()))
+	s.NoError(actual.Set(certPin.String() + ", " + spkiPin.String()))
+	s.Equal(TLSPinsFlag{spkiPin, certPin, spkiPin}, actual)
+	s.Equal(spkiPin.String()+","+certPin.String()+","+spkiPin.String(), actual.String())
+
+	s.NoError(actual.Replace([]string{certPin.String()}))
+	s.Equal(TLSPinsFlag{certPin}, actual)
+	s.Equal([]string{certPin.String()}, actual.GetSlice())
+}
+
+func (s *TLSPinsTestSuite) TestTLSPinsFlagErrors() {
+	testCases := []string{
+		"sha256/AAAA",
+		"not-a-pin",
+		"AB:CD",
+	}
+
+	for _, tc := range testCases {
+		s.T().Run(tc, func(_ *testing.T) {
+			var actual TLSPinsFlag
+
+			err := actual.Set(tc)
+
+			s.True(errors.Is(err, client.ErrInvalidTLSPin))
+		})
+	}
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestRunTLSPinsTestSuite(t *testing.T) {
+	suite.Run(t, new(TLSPinsTestSuite))
+}
@@ -17,6 +17,8 @@ type TLSProtocolsFlag struct {
 	Max client.TLSProtocol
 }
 
+var ErrInvalidTLSProtocols = fmt.Errorf("invalid TLS protocols")
+
 func NewDefaultTLSProtocolsFlag() TLSProtocolsFlag {
 	return TLSProtocolsFlag{
 		Min: client.VersionTLSDefaultMin,
@@ -57,9 +59,9 @@ func (flag *TLSProtocolsFlag) Set(val string) error {
 
 		switch tok {
 		case "SSLv2":
-			return fmt.Errorf("SSLv2 not supported (RFC 6176)")
+			return fmt.Errorf("%w: SSLv2 not supported (RFC 6176)", ErrInvalidTLSProtocols)
 		case "SSLv3":
-			return fmt.Errorf("SSLv3 not supported")
+			return fmt.Errorf("%w: SSLv3 not supported", ErrInvalidTLSProtocols)
 		case "TLSv1":
 			current |= tlsV1
 		case "TLSv1.1":
@@ -71,7 +73,7 @@ func (flag *TLSProtocolsFlag) Set(val string) error {
 		case "all":
 			current |= tlsAll
 		default:
-			return fmt.Errorf("unknown protocol version %s", tok)
+			return fmt.Errorf("%w: unknown protocol version %s", ErrInvalidTLSProtocols, tok)
 		}
 
 		switch sign {
@@ -81,7 +83,8 @@ func (flag *TLSProtocolsFlag) Set(val string) error {
 			protocols &= ^current
 		default:
 			if protocols != 0 {
-				return fmt.Errorf("TLS protocol %s overrides already set parameters. Check if a +/- prefix is missing", tok)
+				return fmt.Errorf("%w: TLS protocol %s overrides already set parameters. Check if a +/- prefix is missing",
+					ErrInvalidTLSProtocols, tok)
 			}
 
 			protocols = current
@@ -97,7 +100,7 @@ func (flag *TLSProtocolsFlag) Set(val string) error {
 
 	if (protocols&tlsV1) != 0 && (protocols&tlsV1_2) != 0 {
 		// Since golangs tls.Config only support min and max we cannot specify 1 & 1.2 without 1.1
-		return fmt.Errorf("you may only specify a range of protocols")
+		return fmt.Errorf("%w: you may only specify a range of protocols", ErrInvalidTLSProtocols)
 	}
 
 	for i, p := range protocolSlice {
@@ -157,3 +160,13 @@ func (flag *TLSProtocolsFlag) String() string {
 
 	return strings.Join(protocols, " ")
 }
+
+// MarshalJSON encodes the protocols in the --tls-protocols format.
+func (flag TLSProtocolsFlag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(flag)
+}
+
+// MarshalYAML encodes the protocols in the --tls-protocols format.
+func (flag TLSProtocolsFlag) MarshalYAML() (any, error) {
+	return flag.String(), nil
+}
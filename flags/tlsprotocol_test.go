@@ -99,7 +99,7 @@ func (s *FlagsTestSuite) TestTLSProtocolsFlag() {
 			err := actual.Set(tc.input)
 
 			if tc.err {
-				s.Error(err)
+				s.ErrorIs(err, ErrInvalidTLSProtocols)
 			} else {
 				s.NoError(err)
 				s.Equal(tc.output, actual)
@@ -0,0 +1,332 @@
+package flags
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/aerospike/tools-common-go/client"
+	"github.com/spf13/pflag"
+)
+
+var ErrInvalidURI = fmt.Errorf("invalid Aerospike URI")
+
+// uriExcludedParams lists the flags that can not be set using URI query
+// parameters, either because another part of the URI sets them or because
+// they hold secrets.
+var uriExcludedParams = []string{"uri", "host", "user", "password", "tls-enable", "tls-keyfile-password"}
+
+// aerospikeURI holds the parts of a parsed aerospike:// or aerospikes:// URI.
+type aerospikeURI struct {
+	user  *url.Userinfo
+	query url.Values
+	hosts []string
+	tls   bool
+}
+
+// parseAerospikeURI splits uri into its parts. The authority is parsed by hand
+// since net/url does not accept a list of hosts or tls-names.
+func parseAerospikeURI(uri string) (*aerospikeURI, error) {
+	u := &aerospikeURI{}
+
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok {
+		return nil, fmt.Errorf("%w: missing scheme", ErrInvalidURI)
+	}
+
+	switch strings.ToLower(scheme) {
+	case client.URISchemeAerospike:
+	case client.URISchemeAerospikeTLS:
+		u.tls = true
+	default:
+		return nil, fmt.Errorf("%w: unsupported scheme %q, expected %q or %q",
+			ErrInvalidURI, scheme, client.URISchemeAerospike, client.URISchemeAerospikeTLS)
+	}
+
+	authority, rawQuery, _ := strings.Cut(rest, "?")
+	authority, path, _ := strings.Cut(authority, "/")
+
+	if path != "" {
+		return nil, fmt.Errorf("%w: unexpected path %q", ErrInvalidURI, path)
+	}
+
+	if idx := strings.LastIndex(authority, "@"); idx >= 0 {
+		user, err := parseURIUserinfo(authority[:idx])
+		if err != nil {
+			return nil, err
+		}
+
+		u.user = user
+		authority = authority[idx+1:]
+	}
+
+	if authority != "" {
+		u.hosts = strings.Split(authority, ",")
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURI, err)
+	}
+
+	for name := range query {
+		if slices.Contains(uriExcludedParams, name) {
+			return nil, fmt.Errorf("%w: %q can not be set as a query parameter", ErrInvalidURI, name)
+		}
+	}
+
+	u.query = query
+
+	return u, nil
+}
+
+func parseURIUserinfo(userinfo string) (*url.Userinfo, error) {
+	rawUser, rawPassword, hasPassword := strings.Cut(userinfo, ":")
+
+	user, err := url.PathUnescape(rawUser)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid user: %w", ErrInvalidURI, err)
+	}
+
+	if !hasPassword {
+		return url.User(user), nil
+	}
+
+	password, err := url.PathUnescape(rawPassword)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid password: %w", ErrInvalidURI, err)
+	}
+
+	return url.UserPassword(user, password), nil
+}
+
+// apply sets the flags of af from the URI and marks them as changed on
+// flagSet, which must have been returned by af.NewFlagSet. Flags that are
+// already changed are left unchanged.
+func (u *aerospikeURI) apply(af *AerospikeFlags, flagSet *pflag.FlagSet) error {
+	// skip reports whether the flag was already changed and otherwise marks
+	// it as changed by the URI.
+	skip := func(name string) bool {
+		flag := flagSet.Lookup(name)
+		if flag == nil {
+			return false
+		}
+
+		if flag.Changed {
+			return true
+		}
+
+		flag.Changed = true
+
+		return false
+	}
+
+	if !skip("tls-enable") {
+		af.TLSEnable = u.tls
+	}
+
+	if u.user != nil {
+		if !skip("user") {
+			af.User = u.user.Username()
+		}
+
+		// The password is used as is rather than being parsed by
+		// PasswordFlag.Set, which would interpret prefixes like "env:".
+		if password, ok := u.user.Password(); ok && !skip("password") {
+			af.Password = PasswordFlag(password)
+		}
+	}
+
+	if len(u.hosts) != 0 && !skip("host") {
+		if err := af.Seeds.Replace(u.hosts); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidURI, err)
+		}
+	}
+
+	for name, vals := range u.query {
+		flag := flagSet.Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("%w: unknown parameter %q", ErrInvalidURI, name)
+		}
+
+		if skip(name) {
+			continue
+		}
+
+		if err := setURIParam(flag.Value, vals); err != nil {
+			return fmt.Errorf("%w: parameter %q: %w", ErrInvalidURI, name, err)
+		}
+	}
+
+	return nil
+}
+
+// setURIParam sets value from the values of a query parameter. A parameter
+// for a slice flag may be repeated, any other parameter must be given once.
+func setURIParam(value pflag.Value, vals []string) error {
+	if sliceValue, ok := value.(pflag.SliceValue); ok {
+		if err := sliceValue.Replace([]string{}); err != nil {
+			return err
+		}
+
+		for _, val := range vals {
+			if err := value.Set(val); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if len(vals) != 1 {
+		return fmt.Errorf("may only be given once")
+	}
+
+	return value.Set(vals[0])
+}
+
+// redacted formats the URI with the password redacted.
+func (u *aerospikeURI) redacted() string {
+	scheme := client.URISchemeAerospike
+
+	if u.tls {
+		scheme = client.URISchemeAerospikeTLS
+	}
+
+	uri := scheme + "://"
+
+	if u.user != nil {
+		user := u.user
+
+		if _, ok := user.Password(); ok {
+			user = url.UserPassword(user.Username(), client.RedactedSecret)
+		}
+
+		uri += user.String() + "@"
+	}
+
+	uri += strings.Join(u.hosts, ",")
+
+	if len(u.query) != 0 {
+		uri += "/?" + u.query.Encode()
+	}
+
+	return uri
+}
+
+// ParseAerospikeURI parses an aerospike:// or aerospikes:// URI of the form
+//
+//	aerospike[s]://[user[:password]@]host[:tls-name][:port][,...][/?flag=value[&...]]
+//
+// into a client.AerospikeConfig. The aerospikes scheme enables TLS. Query
+// parameters are named after the AerospikeFlags flags, e.g. auth=PKI,
+// services-alternate=true or tls-protocols=TLSv1.3, and take the same
+// values. Seeds without a port use the port parameter or DefaultPort.
+func ParseAerospikeURI(uri string) (*client.AerospikeConfig, error) {
+	u, err := parseAerospikeURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	af := NewDefaultAerospikeFlags()
+	flagSet := af.NewFlagSet(DefaultWrapHelpString)
+
+	if err := u.apply(af, flagSet); err != nil {
+		return nil, err
+	}
+
+	return af.NewAerospikeConfig(), nil
+}
+
+// URIFlag defines a Cobra compatible flag for an
+// aerospike:// or aerospikes:// connection URI. See ParseAerospikeURI.
+// The flag registered by AerospikeFlags.NewFlagSet applies the URI to the
+// other flags once, when it is set. Flags changed before that, e.g. given
+// before --uri on the command line, are left unchanged. The flags set from
+// the URI are marked as changed so that a config file loaded afterwards, see
+// config.InitConfig, does not override them.
+// --uri
+type URIFlag struct {
+	uri *aerospikeURI
+}
+
+func (flag *URIFlag) Set(val string) error {
+	if val == "" {
+		flag.uri = nil
+		return nil
+	}
+
+	u, err := parseAerospikeURI(val)
+	if err != nil {
+		return err
+	}
+
+	// Apply the URI to a scratch set of flags so that invalid values are
+	// reported while parsing rather than ignored later.
+	af := NewDefaultAerospikeFlags()
+
+	if err := u.apply(af, af.NewFlagSet(DefaultWrapHelpString)); err != nil {
+		return err
+	}
+
+	flag.uri = u
+
+	return nil
+}
+
+func (flag *URIFlag) Type() string {
+	return "aerospike[s]://[user[:password]@]host[:tls-name][:port][,...][/?flag=value[&...]]"
+}
+
+// String returns the URI with the password redacted.
+func (flag *URIFlag) String() string {
+	if flag.uri == nil {
+		return ""
+	}
+
+	return flag.uri.redacted()
+}
+
+// appliedURIFlag wraps the URIFlag of af and applies the URI to the flags of
+// af when it is set.
+type appliedURIFlag struct {
+	*URIFlag
+	af *AerospikeFlags
+}
+
+func newAppliedURIFlag(af *AerospikeFlags) *appliedURIFlag {
+	return &appliedURIFlag{
+		URIFlag: &af.URI,
+		af:      af,
+	}
+}
+
+func (flag *appliedURIFlag) Set(val string) error {
+	if val == "" {
+		return flag.URIFlag.Set(val)
+	}
+
+	u, err := parseAerospikeURI(val)
+	if err != nil {
+		return err
+	}
+
+	if err := u.apply(flag.af, flag.af.flagSet); err != nil {
+		return err
+	}
+
+	flag.uri = u
+
+	return nil
+}
+
+// MarshalJSON encodes the URI with the password redacted.
+func (flag URIFlag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(flag)
+}
+
+// MarshalYAML encodes the URI with the password redacted.
+func (flag URIFlag) MarshalYAML() (any, error) {
+	return flag.String(), nil
+}
@@ -3,6 +3,7 @@ package flags
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -63,7 +64,7 @@ func readFromFile(filePath string, removeTrailingNewLine bool) ([]byte, error) {
 
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read from file `%s`: `%v`", filePath, err)
+		return nil, fmt.Errorf("failed to read from file `%s`: `%w`", filePath, err)
 	}
 
 	if removeTrailingNewLine {
@@ -79,7 +80,7 @@ func readFromPath(filePath string, removeTrailingNewLine bool) ([][]byte, error)
 
 	fileSysInfo, err := os.ReadDir(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read from file `%s`: `%v`", filePath, err)
+		return nil, fmt.Errorf("failed to read from file `%s`: `%w`", filePath, err)
 	}
 
 	result := make([][]byte, len(fileSysInfo))
@@ -87,7 +88,7 @@ func readFromPath(filePath string, removeTrailingNewLine bool) ([][]byte, error)
 	for i, file := range fileSysInfo {
 		data, err := readFromFile(filepath.Join(filePath, file.Name()), removeTrailingNewLine)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read from file `%s`: `%v`", file.Name(), err)
+			return nil, fmt.Errorf("failed to read from file `%s`: `%w`", file.Name(), err)
 		}
 
 		result[i] = data
@@ -95,3 +96,18 @@ func readFromPath(filePath string, removeTrailingNewLine bool) ([][]byte, error)
 
 	return result, nil
 }
+
+type yamlMarshaler interface {
+	MarshalYAML() (any, error)
+}
+
+// marshalJSON encodes the MarshalYAML value of a flag so that both encodings
+// show the same, possibly redacted, value.
+func marshalJSON(flag yamlMarshaler) ([]byte, error) {
+	val, err := flag.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(val)
+}
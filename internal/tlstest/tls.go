@@ -0,0 +1,361 @@
+// Package tlstest generates certificates, keys, CRLs and OCSP responses for
+// tests. testutils exports them to other modules.
+package tlstest
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des" //nolint:gosec // Used to generate legacy encrypted keys
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// CATemplate is a template for a self-signed certificate.
+var CATemplate = &x509.Certificate{
+	SerialNumber: big.NewInt(1),
+	Subject: pkix.Name{
+		Country:      []string{"SE"},
+		Organization: []string{"Company Co."},
+		CommonName:   "Root CA",
+	},
+	NotBefore:             time.Now().Add(-10 * time.Second),
+	NotAfter:              time.Now().AddDate(10, 0, 0),
+	KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	BasicConstraintsValid: true,
+	IsCA:                  true,
+	MaxPathLen:            2,
+	IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+}
+
+var CAKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+
+// KeyFileBytes is a PEM encoded private key.
+var KeyFileBytes = pem.EncodeToMemory(
+	&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(CAKey),
+	},
+)
+
+func GenerateCert() ([]byte, error) {
+	// Create a self-signed certificate. template = parent
+	// The parent is always allowed to sign a child
+	certBytes, err := x509.CreateCertificate(rand.Reader, CATemplate, CATemplate, &CAKey.PublicKey, CAKey)
+	if err != nil {
+		return nil, err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: certBytes,
+	})
+
+	return certPEM, nil
+}
+
+// LeafKey is the private key of the certificates created by GenerateLeafCert.
+var LeafKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+
+// LeafKeyFileBytes is a PEM encoded LeafKey.
+var LeafKeyFileBytes = pem.EncodeToMemory(
+	&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(LeafKey),
+	},
+)
+
+// NewLeafTemplate returns a template for a certificate valid for localhost
+// and 127.0.0.1 that can be used for both server and client authentication.
+func NewLeafTemplate(serial int64) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject: pkix.Name{
+			Country:      []string{"SE"},
+			Organization: []string{"Company Co."},
+			CommonName:   "localhost",
+		},
+		NotBefore:   time.Now().Add(-10 * time.Second),
+		NotAfter:    time.Now().AddDate(1, 0, 0),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+	}
+}
+
+// GenerateLeafCert creates a PEM encoded certificate for LeafKey from the
+// template signed by the CA returned by GenerateCert.
+func GenerateLeafCert(template *x509.Certificate) ([]byte, error) {
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, CATemplate, &LeafKey.PublicKey, CAKey)
+	if err != nil {
+		return nil, err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: certBytes,
+	})
+
+	return certPEM, nil
+}
+
+func parseCA() (*x509.Certificate, error) {
+	caPEM, err := GenerateCert()
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(caPEM)
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// GenerateCRL creates a PEM encoded certificate revocation list signed by the
+// CA returned by GenerateCert that revokes the given serial numbers.
+func GenerateCRL(revokedSerials ...int64) ([]byte, error) {
+	return GenerateCRLWithNextUpdate(time.Now().AddDate(0, 0, 7), revokedSerials...)
+}
+
+// GenerateCRLWithNextUpdate is GenerateCRL with the given next update, which
+// may be in the past to create an expired CRL.
+func GenerateCRLWithNextUpdate(nextUpdate time.Time, revokedSerials ...int64) ([]byte, error) {
+	ca, err := parseCA()
+	if err != nil {
+		return nil, err
+	}
+
+	thisUpdate := time.Now().Add(-10 * time.Second)
+	if nextUpdate.Before(thisUpdate) {
+		thisUpdate = nextUpdate.Add(-time.Hour)
+	}
+
+	template := &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: thisUpdate,
+		NextUpdate: nextUpdate,
+	}
+
+	for _, serial := range revokedSerials {
+		template.RevokedCertificateEntries = append(template.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   big.NewInt(serial),
+			RevocationTime: time.Now().Add(-time.Second),
+		})
+	}
+
+	crlBytes, err := x509.CreateRevocationList(rand.Reader, template, ca, CAKey)
+	if err != nil {
+		return nil, err
+	}
+
+	crlPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "X509 CRL",
+		Bytes: crlBytes,
+	})
+
+	return crlPEM, nil
+}
+
+// GenerateOCSPResponse creates a DER encoded OCSP response signed by the CA
+// returned by GenerateCert for the PEM encoded certificate. Status is one of
+// ocsp.Good, ocsp.Revoked or ocsp.Unknown.
+func GenerateOCSPResponse(certPEM []byte, status int) ([]byte, error) {
+	ca, err := parseCA()
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(certPEM)
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	template := ocsp.Response{
+		Status:       status,
+		SerialNumber: cert.SerialNumber,
+		ThisUpdate:   time.Now().Add(-10 * time.Second),
+		NextUpdate:   time.Now().AddDate(0, 0, 7),
+	}
+
+	if status == ocsp.Revoked {
+		template.RevokedAt = time.Now().Add(-time.Second)
+	}
+
+	return ocsp.CreateResponse(ca, ca, template, CAKey)
+}
+
+// GeneratePKCS12 creates a PKCS#12 bundle containing LeafKey, the certificate
+// created by GenerateLeafCert from the template, and the CA returned by
+// GenerateCert, encrypted with the password.
+func GeneratePKCS12(template *x509.Certificate, password string) ([]byte, error) {
+	ca, err := parseCA()
+	if err != nil {
+		return nil, err
+	}
+
+	certPEM, err := GenerateLeafCert(template)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(certPEM)
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkcs12.Modern.Encode(LeafKey, cert, []*x509.Certificate{ca}, password)
+}
+
+// PKCS8Cipher selects the PBES2 encryption scheme used by
+// EncryptPKCS8PrivateKey.
+type PKCS8Cipher int
+
+const (
+	PKCS8AES128CBC PKCS8Cipher = iota
+	PKCS8AES256CBC
+	PKCS8AES256GCM
+	PKCS8DESEDE3CBC
+)
+
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidDESEDE3CBC     = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidAES256GCM      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 46}
+)
+
+type pkcs8AlgorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue
+}
+
+// EncryptPKCS8PrivateKey returns key as a PEM "ENCRYPTED PRIVATE KEY" block
+// encrypted with PBES2 using PBKDF2 with HMAC-SHA256 and the given cipher.
+// This matches the output of "openssl pkcs8 -topk8 -v2 <cipher>".
+func EncryptPKCS8PrivateKey(key any, password []byte, c PKCS8Cipher) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err = rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	var (
+		keyLen    int
+		schemeOID asn1.ObjectIdentifier
+		newBlock  = aes.NewCipher
+	)
+
+	switch c {
+	case PKCS8AES128CBC:
+		keyLen, schemeOID = 16, oidAES128CBC
+	case PKCS8AES256CBC:
+		keyLen, schemeOID = 32, oidAES256CBC
+	case PKCS8AES256GCM:
+		keyLen, schemeOID = 32, oidAES256GCM
+	case PKCS8DESEDE3CBC:
+		keyLen, schemeOID, newBlock = 24, oidDESEDE3CBC, des.NewTripleDESCipher
+	}
+
+	iterations := 2048
+
+	encKey, err := pbkdf2.Key(sha256.New, string(password), salt, iterations, keyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := newBlock(encKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemeParams, encrypted []byte
+
+	if c == PKCS8AES256GCM {
+		nonce := make([]byte, 12)
+		if _, err = rand.Read(nonce); err != nil {
+			return nil, err
+		}
+
+		aead, gcmErr := cipher.NewGCM(block)
+		if gcmErr != nil {
+			return nil, gcmErr
+		}
+
+		encrypted = aead.Seal(nil, nonce, der, nil)
+		schemeParams, err = asn1.Marshal(struct {
+			Nonce  []byte
+			ICVLen int
+		}{nonce, aead.Overhead()})
+	} else {
+		iv := make([]byte, block.BlockSize())
+		if _, err = rand.Read(iv); err != nil {
+			return nil, err
+		}
+
+		padLen := block.BlockSize() - len(der)%block.BlockSize()
+		encrypted = append(der, bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, encrypted)
+		schemeParams, err = asn1.Marshal(iv)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	kdfParams, err := asn1.Marshal(struct { //nolint:govet // field order is defined by the ASN.1 structure
+		Salt           []byte
+		IterationCount int
+		PRF            pkcs8AlgorithmIdentifier
+	}{salt, iterations, pkcs8AlgorithmIdentifier{oidHMACWithSHA256, asn1.NullRawValue}})
+	if err != nil {
+		return nil, err
+	}
+
+	pbes2Params, err := asn1.Marshal(struct {
+		KeyDerivationFunc pkcs8AlgorithmIdentifier
+		EncryptionScheme  pkcs8AlgorithmIdentifier
+	}{
+		pkcs8AlgorithmIdentifier{oidPBKDF2, asn1.RawValue{FullBytes: kdfParams}},
+		pkcs8AlgorithmIdentifier{schemeOID, asn1.RawValue{FullBytes: schemeParams}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedDER, err := asn1.Marshal(struct {
+		Algorithm     pkcs8AlgorithmIdentifier
+		EncryptedData []byte
+	}{pkcs8AlgorithmIdentifier{oidPBES2, asn1.RawValue{FullBytes: pbes2Params}}, encrypted})
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "ENCRYPTED PRIVATE KEY",
+		Bytes: encryptedDER,
+	}), nil
+}
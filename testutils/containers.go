@@ -10,7 +10,7 @@ import (
 	"text/template"
 	"time"
 
-	"github.com/aerospike/aerospike-client-go/v8"
+	asclient "github.com/aerospike/tools-common-go/client"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/mount"
@@ -233,32 +233,28 @@ func createConfigFile(portBase int, accessAddress, peerConnection, featKeyEnvVar
 }
 
 func waitForASDToStart(name string) error {
-	startTime := time.Now()
-	timeout := 10 * time.Second
-	policy := aerospike.NewClientPolicy()
-	policy.User = User
-	policy.Password = Password
-
-	for {
-		asClient, err := aerospike.NewClientWithPolicy(
-			policy, IP, PortStart)
-		if err == nil {
-			if asClient.IsConnected() {
-				break
-			}
-
-			asClient.Close()
-		}
-
-		if time.Since(startTime) > timeout {
-			log.Printf("Timed out waiting for asd %s to start %s", name, err)
-			return err
-		}
-
-		log.Printf("Waiting for asd %s to start %s", name, err)
-		time.Sleep(1 * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	config := asclient.NewDefaultAerospikeConfig()
+	config.Seeds = asclient.HostTLSPortSlice{{Host: IP, Port: PortStart}}
+	config.User = User
+	config.Password = Password
+
+	asClient, err := asclient.Connect(ctx, config, asclient.ConnectOptions{
+		Retry: asclient.RetryPolicy{
+			MaxAttempts:    10,
+			InitialBackoff: time.Second,
+			MaxBackoff:     time.Second,
+			Multiplier:     1,
+		},
+	})
+	if err != nil {
+		log.Printf("Timed out waiting for asd %s to start: %s", name, err)
+		return err
 	}
 
+	asClient.Close()
 	log.Printf("asd %s started", name)
 
 	return nil
@@ -372,7 +368,7 @@ func RunAerospikeContainer(
 	log.Printf("Started container %s with IP %s", name, containerIP)
 	log.Printf("Waiting for asd %s to start", name)
 
-	if waitForASDToStart(name) != nil {
+	if err := waitForASDToStart(name); err != nil {
 		return nil, err
 	}
 
@@ -456,7 +452,7 @@ func RestartAerospikeContainer(name, confFileContents string) error {
 	log.Printf("Restarted container %s with IP %s", name, ip)
 	log.Printf("Waiting for asd %s to start", name)
 
-	if waitForASDToStart(name) != nil {
+	if err := waitForASDToStart(name); err != nil {
 		return err
 	}
 
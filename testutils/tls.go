@@ -1,56 +1,88 @@
 package testutils
 
 import (
-	"crypto/rand"
-	"crypto/rsa"
 	"crypto/x509"
-	"crypto/x509/pkix"
-	"encoding/pem"
-	"math/big"
-	"net"
 	"time"
+
+	"github.com/aerospike/tools-common-go/internal/tlstest"
 )
 
+// The certificate helpers live in internal/tlstest so that the client tests
+// can use them without importing testutils, which imports client.
+
 // CATemplate is a template for a self-signed certificate.
-var CATemplate = &x509.Certificate{
-	SerialNumber: big.NewInt(1),
-	Subject: pkix.Name{
-		Country:      []string{"SE"},
-		Organization: []string{"Company Co."},
-		CommonName:   "Root CA",
-	},
-	NotBefore:             time.Now().Add(-10 * time.Second),
-	NotAfter:              time.Now().AddDate(10, 0, 0),
-	KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
-	ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
-	BasicConstraintsValid: true,
-	IsCA:                  true,
-	MaxPathLen:            2,
-	IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
-}
-
-var CAKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+var CATemplate = tlstest.CATemplate
+
+// CAKey is the private key of the CA created by GenerateCert.
+var CAKey = tlstest.CAKey
 
 // KeyFileBytes is a PEM encoded private key.
-var KeyFileBytes = pem.EncodeToMemory(
-	&pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(CAKey),
-	},
-)
+var KeyFileBytes = tlstest.KeyFileBytes
+
+// LeafKey is the private key of the certificates created by GenerateLeafCert.
+var LeafKey = tlstest.LeafKey
+
+// LeafKeyFileBytes is a PEM encoded LeafKey.
+var LeafKeyFileBytes = tlstest.LeafKeyFileBytes
 
+// GenerateCert creates a PEM encoded self-signed CA certificate from
+// CATemplate.
 func GenerateCert() ([]byte, error) {
-	// Create a self-signed certificate. template = parent
-	// The parent is always allowed to sign a child
-	certBytes, err := x509.CreateCertificate(rand.Reader, CATemplate, CATemplate, &CAKey.PublicKey, CAKey)
-	if err != nil {
-		return nil, err
-	}
-
-	certPEM := pem.EncodeToMemory(&pem.Block{
-		Type:  "CERTIFICATE",
-		Bytes: certBytes,
-	})
-
-	return certPEM, nil
+	return tlstest.GenerateCert()
+}
+
+// NewLeafTemplate returns a template for a certificate valid for localhost
+// and 127.0.0.1 that can be used for both server and client authentication.
+func NewLeafTemplate(serial int64) *x509.Certificate {
+	return tlstest.NewLeafTemplate(serial)
+}
+
+// GenerateLeafCert creates a PEM encoded certificate for LeafKey from the
+// template signed by the CA returned by GenerateCert.
+func GenerateLeafCert(template *x509.Certificate) ([]byte, error) {
+	return tlstest.GenerateLeafCert(template)
+}
+
+// GenerateCRL creates a PEM encoded certificate revocation list signed by the
+// CA returned by GenerateCert that revokes the given serial numbers.
+func GenerateCRL(revokedSerials ...int64) ([]byte, error) {
+	return tlstest.GenerateCRL(revokedSerials...)
+}
+
+// GenerateCRLWithNextUpdate is GenerateCRL with the given next update, which
+// may be in the past to create an expired CRL.
+func GenerateCRLWithNextUpdate(nextUpdate time.Time, revokedSerials ...int64) ([]byte, error) {
+	return tlstest.GenerateCRLWithNextUpdate(nextUpdate, revokedSerials...)
+}
+
+// GenerateOCSPResponse creates a DER encoded OCSP response signed by the CA
+// returned by GenerateCert for the PEM encoded certificate. Status is one of
+// ocsp.Good, ocsp.Revoked or ocsp.Unknown.
+func GenerateOCSPResponse(certPEM []byte, status int) ([]byte, error) {
+	return tlstest.GenerateOCSPResponse(certPEM, status)
+}
+
+// GeneratePKCS12 creates a PKCS#12 bundle containing LeafKey, the certificate
+// created by GenerateLeafCert from the template, and the CA returned by
+// GenerateCert, encrypted with the password.
+func GeneratePKCS12(template *x509.Certificate, password string) ([]byte, error) {
+	return tlstest.GeneratePKCS12(template, password)
+}
+
+// PKCS8Cipher selects the PBES2 encryption scheme used by
+// EncryptPKCS8PrivateKey.
+type PKCS8Cipher = tlstest.PKCS8Cipher
+
+const (
+	PKCS8AES128CBC  = tlstest.PKCS8AES128CBC
+	PKCS8AES256CBC  = tlstest.PKCS8AES256CBC
+	PKCS8AES256GCM  = tlstest.PKCS8AES256GCM
+	PKCS8DESEDE3CBC = tlstest.PKCS8DESEDE3CBC
+)
+
+// EncryptPKCS8PrivateKey returns key as a PEM "ENCRYPTED PRIVATE KEY" block
+// encrypted with PBES2 using PBKDF2 with HMAC-SHA256 and the given cipher.
+// This matches the output of "openssl pkcs8 -topk8 -v2 <cipher>".
+func EncryptPKCS8PrivateKey(key any, password []byte, c PKCS8Cipher) ([]byte, error) {
+	return tlstest.EncryptPKCS8PrivateKey(key, password, c)
 }